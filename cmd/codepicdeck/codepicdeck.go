@@ -74,7 +74,6 @@ func swapext(s, fromext, toext string) string {
 
 // codepic makes a code and picture slides
 func codepic(deck *generate.Deck, filenames []string) {
-	slide := 0
 	for _, codefile := range filenames {
 		imagefile := swapext(codefile, ".go", ".png")
 		if len(imagefile) == 0 {
@@ -82,12 +81,11 @@ func codepic(deck *generate.Deck, filenames []string) {
 			continue
 		}
 		imw, imh := imagesize(imagefile)
-		slide++
 		deck.StartSlide()
 		deck.Image(75, 68, imw, imh, imagefile, "")
 		deck.Text(2.5, 96, includefile(codefile), "mono", 1.2, "black")
 		deck.TextEnd(90, 2.5, codefile, "sans", 2, "black")
-		deck.TextEnd(95, 2.5, fmt.Sprintf("[%d]", slide), "sans", 2, "gray")
+		deck.TextEnd(95, 2.5, "[{{slidenumber}}]", "sans", 2, "gray")
 		deck.EndSlide()
 	}
 }
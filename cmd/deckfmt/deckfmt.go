@@ -0,0 +1,123 @@
+// deckfmt: format deck markup in canonical layout
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/ajstarks/deck"
+)
+
+var (
+	list  = flag.Bool("l", false, "list files whose formatting differs from deckfmt's")
+	write = flag.Bool("w", false, "write result to (source) file instead of standard output")
+	diffs = flag.Bool("d", false, "display diffs instead of rewriting files")
+)
+
+// process formats markup read from r, named filename
+func process(filename string, r io.Reader, stdin bool) error {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	res, err := deck.Format(src)
+	if err != nil {
+		return fmt.Errorf("%s: %v", filename, err)
+	}
+	if !*list && !*write && !*diffs {
+		_, err = os.Stdout.Write(res)
+		return err
+	}
+	if bytes.Equal(src, res) {
+		return nil
+	}
+	if *list {
+		fmt.Println(filename)
+	}
+	if *write && !stdin {
+		info, err := os.Stat(filename)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filename, res, info.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+	if *diffs {
+		d, err := diff(filename, src, res)
+		if err != nil {
+			return fmt.Errorf("computing diff: %v", err)
+		}
+		os.Stdout.Write(d)
+	}
+	return nil
+}
+
+// diff returns the unified diff of the original and formatted markup
+func diff(filename string, b1, b2 []byte) ([]byte, error) {
+	f1, err := tempfile(b1)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f1)
+	f2, err := tempfile(b2)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f2)
+	out, err := exec.Command("diff", "-u", "-L", filename+".orig", "-L", filename, f1, f2).Output()
+	if len(out) > 0 {
+		// diff exits with status 1 when the files differ
+		return out, nil
+	}
+	return out, err
+}
+
+// tempfile writes data to a temporary file, returning its name
+func tempfile(data []byte) (string, error) {
+	f, err := os.CreateTemp("", "deckfmt")
+	if err != nil {
+		return "", err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return f.Name(), err
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: deckfmt [-l] [-w] [-d] [file...]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	status := 0
+	if flag.NArg() == 0 {
+		if *write {
+			fmt.Fprintln(os.Stderr, "deckfmt: cannot use -w with standard input")
+			os.Exit(2)
+		}
+		if err := process("<standard input>", os.Stdin, true); err != nil {
+			fmt.Fprintf(os.Stderr, "deckfmt: %v\n", err)
+			status = 2
+		}
+		os.Exit(status)
+	}
+	for _, filename := range flag.Args() {
+		f, err := os.Open(filename)
+		if err == nil {
+			err = process(filename, f, false)
+			f.Close()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "deckfmt: %v\n", err)
+			status = 2
+		}
+	}
+	os.Exit(status)
+}
@@ -0,0 +1,266 @@
+// deckvet: report problems in deck markup
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ajstarks/deck"
+)
+
+// position is the location of an element within a deck
+type position struct {
+	slide int
+	kind  string
+	index int
+}
+
+// locations are the source locations (file:line) of elements in deck markup
+type locations struct {
+	lines     map[position]string
+	templates map[string]map[string][]string // template name -> element kind -> locations
+	uses      map[int]string                 // slide -> template name
+}
+
+// where returns the location of a problem; the elements inherited from
+// a template are the slide's first group, ahead of the slide's own groups.
+func (l locations) where(p deck.Problem) string {
+	if t, ok := l.uses[p.Slide]; ok && p.Index >= 0 && l.inherits(t) {
+		g, inner, ok := ingroup(p.Kind)
+		switch {
+		case p.Kind == "group" && p.Index == 0:
+			return l.lines[position{p.Slide, "slide", -1}]
+		case p.Kind == "group":
+			p.Index--
+		case ok && g == 1:
+			// elements within the template's groups are located at the group
+			kind, index := inner, p.Index
+			if tg, _, ok := ingroup(inner); ok {
+				kind, index = "group", tg-1
+			}
+			if tl := l.templates[t][kind]; index < len(tl) {
+				return tl[index]
+			}
+			return l.lines[position{p.Slide, "slide", -1}]
+		case ok:
+			p.Kind = fmt.Sprintf("group %d: %s", g-1, inner)
+		}
+	}
+	if loc, ok := l.lines[position{p.Slide, p.Kind, p.Index}]; ok {
+		return loc
+	}
+	return l.lines[position{p.Slide, "slide", -1}]
+}
+
+// inherits reports whether template t has elements, inherited by the slides that use it
+func (l locations) inherits(t string) bool {
+	for kind := range l.templates[t] {
+		if kind != "note" {
+			return true
+		}
+	}
+	return false
+}
+
+// ingroup splits the kind of an element within a group ("group 2: rect")
+// into the number of the outermost group and the kind within it
+func ingroup(kind string) (int, string, bool) {
+	rest, ok := strings.CutPrefix(kind, "group ")
+	if !ok {
+		return 0, "", false
+	}
+	num, inner, ok := strings.Cut(rest, ": ")
+	g, err := strconv.Atoi(num)
+	if !ok || err != nil || g < 1 {
+		return 0, "", false
+	}
+	return g, inner, true
+}
+
+// scanner finds the locations of elements, following includes
+type scanner struct {
+	loc    locations
+	slide  int
+	count  map[string]int
+	chain  []string
+	groups []string // for each open element within a slide, the location prefix of its children ("-" if not located)
+}
+
+// vet reports problems in the named deck file, returning the number found
+func vet(filename string, w, h int) int {
+	var d deck.Deck
+	var data []byte
+	var err error
+	opts := deck.ReadOptions{Strict: true}
+	if filename == "-" {
+		data, err = io.ReadAll(os.Stdin)
+		if err == nil {
+			d, err = deck.ReadDeckOptions(io.NopCloser(bytes.NewReader(data)), w, h, opts)
+		}
+	} else {
+		data, err = os.ReadFile(filename)
+		if err == nil {
+			d, err = deck.ReadWithOptions(filename, w, h, opts)
+		}
+	}
+	n := 0
+	if errs, ok := err.(deck.MarkupErrors); ok {
+		for _, e := range errs {
+			if e.File == "" {
+				e.File = filename
+			}
+			fmt.Fprintln(os.Stderr, e)
+		}
+		n = len(errs)
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "deckvet: %v\n", err)
+		return 1
+	}
+	s := &scanner{
+		loc: locations{
+			lines:     map[position]string{},
+			templates: map[string]map[string][]string{},
+			uses:      map[int]string{},
+		},
+		slide: -1,
+	}
+	// JSON and YAML decks have no markup to scan: their problems are located by file
+	if err := s.scan(filename, data, 0); err != nil && n > 0 {
+		return n
+	}
+	problems := deck.Validate(d)
+	for _, p := range problems {
+		where := s.loc.where(p)
+		if where == "" {
+			where = filename
+		}
+		fmt.Fprintf(os.Stderr, "%s: %v\n", where, p)
+	}
+	return n + len(problems)
+}
+
+// scan records the locations of the deck, slides, templates and their elements.
+// level is 0 for the deck file, 1 for files included at the deck level,
+// and 2 for files included within a slide.
+func (s *scanner) scan(filename string, data []byte, level int) error {
+	for _, f := range s.chain {
+		if f == filename {
+			return fmt.Errorf("include cycle")
+		}
+	}
+	s.chain = append(s.chain, filename)
+	defer func() { s.chain = s.chain[:len(s.chain)-1] }()
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	depth := 0
+	var template map[string][]string
+	for {
+		line, _ := dec.InputPos()
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			name := t.Name.Local
+			// a file containing a single slide is treated as a deck of one slide
+			if depth == 1 && name == "slide" {
+				depth++
+			}
+			where := fmt.Sprintf("%s:%d", filename, line)
+			switch {
+			case name == "include" && (depth == 2 || depth == 3):
+				file := attr(t, "file")
+				if !filepath.IsAbs(file) {
+					file = filepath.Join(filepath.Dir(filename), file)
+				}
+				data, err := os.ReadFile(file)
+				if err != nil {
+					return err
+				}
+				if err := s.scan(file, data, depth-1); err != nil {
+					return err
+				}
+			case depth == 1 && level == 0:
+				s.loc.lines[position{-1, "", -1}] = where
+			case depth == 2 && name == "slide" && level < 2:
+				s.slide++
+				s.count = map[string]int{}
+				template = nil
+				s.loc.lines[position{s.slide, "slide", -1}] = where
+				if tn := attr(t, "template"); tn != "" {
+					s.loc.uses[s.slide] = tn
+				}
+			case depth == 2 && name == "template" && level < 2:
+				template = map[string][]string{}
+				s.loc.templates[attr(t, "name")] = template
+			case depth == 3 && template != nil:
+				template[name] = append(template[name], where)
+			}
+			// elements of slides and groups
+			if depth >= 3 {
+				parent := ""
+				if depth > 3 {
+					parent = s.groups[len(s.groups)-1]
+				}
+				child := "-" // the content of elements other than groups is not located
+				if parent != "-" && template == nil && s.slide >= 0 && name != "include" {
+					kind := parent + name
+					n := s.count[kind]
+					s.loc.lines[position{s.slide, kind, n}] = where
+					s.count[kind]++
+					if name == "group" {
+						child = fmt.Sprintf("%sgroup %d: ", parent, n+1)
+					}
+				}
+				s.groups = append(s.groups, child)
+			}
+		case xml.EndElement:
+			if depth >= 3 {
+				s.groups = s.groups[:len(s.groups)-1]
+			}
+			depth--
+			if depth == 1 {
+				template = nil
+			}
+		}
+	}
+}
+
+// attr returns the value of the named attribute
+func attr(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func main() {
+	var width = flag.Int("w", 792, "canvas width")
+	var height = flag.Int("h", 612, "canvas height")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: deckvet [-w width -h height] file...")
+		os.Exit(2)
+	}
+	nproblems := 0
+	for _, filename := range flag.Args() {
+		nproblems += vet(filename, *width, *height)
+	}
+	if nproblems > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/ajstarks/deck"
+)
+
+func TestWhere(t *testing.T) {
+	data := []byte(`<deck>
+<template name="t">
+<rect xp="1"/>
+<group><rect xp="2"/></group>
+</template>
+<slide template="t">
+<rect xp="3"/>
+<group>
+<rect xp="4"/>
+<group><rect xp="5"/></group>
+</group>
+</slide>
+</deck>`)
+	s := &scanner{
+		loc:   locations{lines: map[position]string{}, templates: map[string]map[string][]string{}, uses: map[int]string{}},
+		slide: -1,
+	}
+	if err := s.scan("t.xml", data, 0); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		p    deck.Problem
+		want string
+	}{
+		{deck.Problem{Slide: 0, Kind: "group 1: rect", Index: 0}, "t.xml:3"},
+		{deck.Problem{Slide: 0, Kind: "group 1: group", Index: 0}, "t.xml:4"},
+		{deck.Problem{Slide: 0, Kind: "group 1: group 1: rect", Index: 0}, "t.xml:4"},
+		{deck.Problem{Slide: 0, Kind: "rect", Index: 0}, "t.xml:7"},
+		{deck.Problem{Slide: 0, Kind: "group", Index: 1}, "t.xml:8"},
+		{deck.Problem{Slide: 0, Kind: "group 2: rect", Index: 0}, "t.xml:9"},
+		{deck.Problem{Slide: 0, Kind: "group 2: group 1: rect", Index: 0}, "t.xml:10"},
+	}
+	for _, test := range tests {
+		if got := s.loc.where(test.p); got != test.want {
+			t.Errorf("%v: got %s, want %s", test.p, got, test.want)
+		}
+	}
+}
@@ -4,33 +4,26 @@ package main
 import (
 	"flag"
 	"fmt"
-	"image"
 	"image/color"
 	"os"
 	"os/signal"
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 	"unicode"
 
-	_ "image/gif"
-	_ "image/jpeg"
-	_ "image/png"
-
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/ajstarks/deck"
+	"github.com/ajstarks/deck/render"
 	"github.com/ajstarks/fc"
 )
 
 const (
-	mm2pt       = 2.83464 // mm to pt conversion
-	linespacing = 1.8
-	listspacing = 1.8
-	fontfactor  = 1.0
-	listwrap    = 95.0
+	mm2pt = 2.83464 // mm to pt conversion
 )
 
 // PageDimen describes page dimensions
@@ -57,10 +50,26 @@ var pagemap = map[string]PageDimen{
 	"A5":         {210, 148, mm2pt},
 }
 
-var codemap = strings.NewReplacer("\t", "    ")
-
 var gridstate bool
 
+// buildstep is the build step shown of the current slide
+var buildstep = 1
+
+// playing reports whether slides advance by themselves
+var playing bool
+
+// defaultpause is how long slides without their own duration show while playing, unless set by -loop
+const defaultpause = 5 * time.Second
+
+// events are run in turn by the event loop in main, which alone changes the slides and
+// what is shown; the version of fyne used has no way of running them on its own loop
+var events = make(chan func(), 16)
+
+// do runs f on the event loop
+func do(f func()) {
+	events <- f
+}
+
 // setpagesize parses the page size string (wxh)
 func setpagesize(s string) (float64, float64) {
 	var width, height float64
@@ -94,378 +103,174 @@ func pagedim(s string) (int, int) {
 	return int(pw), int(ph)
 }
 
-// includefile returns the contents of a file as string
-func includefile(filename string) string {
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "%v\n", err)
-		return ""
-	}
-	return codemap.Replace(string(data))
-}
-
 // pct converts percentages to canvas measures
 func pct(p, m float64) float64 {
 	return (p / 100.0) * m
 }
 
-// fontlookup maps font aliases to implementation font names
-func fontlookup(s string) string {
-	font, ok := fontmap[s]
-	if ok {
-		return font
-	}
-	return "sans"
-}
-
-// grid makes a percentage scale
-func grid(doc *fc.Canvas, w, h float64, color string, percent float64) {
-	c := fc.ColorLookup(color)
-	c.A = 100
-	fs := pct(1.5, w)
-	for x, pl := 0.0, 0.0; x <= w; x += percent {
-		doc.Line(x, 0, x, h, 0.1, c)
-		if pl > 0 {
-			doc.CText(x, fs, fs, fmt.Sprintf("%.0f", pl), c)
-		}
-		pl += percent
-	}
-	for y, pl := 0.0, 0.0; y <= h; y += percent {
-		doc.Line(0, y, w, y, 0.1, c)
-		if pl < 100 {
-			doc.Text(fs, y+(fs/3), fs, fmt.Sprintf("%.0f", pl), c)
-		}
-		pl += percent
-	}
-	doc.Container.Refresh()
+// fcdoc draws slide elements on a fyne canvas;
+// canvas units are converted to the percentage coordinates used by fc,
+// which has its origin at the lower left. The slide is drawn scaled,
+// with its lower left corner at (left, bottom).
+type fcdoc struct {
+	doc          *fc.Canvas
+	cw, ch       float64
+	left, bottom float64
+	scale        float64
 }
 
-// setop sets the opacity as a truncated fraction of 255
-func setop(v float64) uint8 {
-	if v > 0.0 {
-		return uint8(255.0 * (v / 100.0))
-	}
-	return 255
+// slidedoc draws slides filling the canvas
+func slidedoc(doc *fc.Canvas, d *deck.Deck) fcdoc {
+	return fcdoc{doc: doc, cw: float64(d.Canvas.Width), ch: float64(d.Canvas.Height), scale: 1}
 }
 
-// background places a colored rectangle
-func background(doc *fc.Canvas, w, h float64, color string) {
-	doc.Rect(w/2, h/2, w, h, fc.ColorLookup(color))
+// xp converts a canvas x coordinate to a percentage
+func (p fcdoc) xp(x float64) float64 {
+	return p.left + p.wp(x)
 }
 
-// gradient sets the background color gradient
-func gradient(doc *fc.Canvas, w, h float64, gc1, gc2 string, gp float64) {
+// yp converts a canvas y coordinate to a percentage
+func (p fcdoc) yp(y float64) float64 {
+	return p.bottom + (100-((y/p.ch)*100))*p.scale
 }
 
-// doline draws a line
-func doline(doc *fc.Canvas, xp1, yp1, xp2, yp2, sw float64, color string, opacity float64) {
-	c := fc.ColorLookup(color)
-	c.A = setop(opacity)
-	doc.Line(xp1, yp1, xp2, yp2, sw, c)
+// wp converts a canvas width to a percentage of the canvas width
+func (p fcdoc) wp(w float64) float64 {
+	return (w / p.cw) * 100 * p.scale
 }
 
-// doarc draws an arc
-func doarc(doc *fc.Canvas, x, y, w, h, a1, a2, sw float64, color string, opacity float64) {
+// hp converts a canvas height to a percentage of the canvas height
+func (p fcdoc) hp(h float64) float64 {
+	return (h / p.ch) * 100 * p.scale
 }
 
-// docurve draws a bezier curve
-func docurve(doc *fc.Canvas, xp1, yp1, xp2, yp2, xp3, yp3, sw float64, color string, opacity float64) {
+// color returns the color at the specified opacity
+func (p fcdoc) color(s render.Style) color.RGBA {
+	c, alpha := render.Color(s.Color, s.Opacity)
+	c.A = uint8(255 * alpha)
+	return c
 }
 
-// dorect draws a rectangle
-func dorect(doc *fc.Canvas, x, y, w, h float64, color string, opacity float64) {
-	c := fc.ColorLookup(color)
-	c.A = setop(opacity)
-	doc.Rect(x, y, w, h, c)
+// segments strokes a series of connected points, dashed if the style has a dash pattern
+func (p fcdoc) segments(x, y []float64, s render.Style) {
+	c := p.color(s)
+	sw := p.wp(s.Width)
+	dx, dy := render.Dashes(x, y, s.Dash)
+	for d := range dx {
+		for i := 1; i < len(dx[d]); i++ {
+			p.doc.Line(p.xp(dx[d][i-1]), p.yp(dy[d][i-1]), p.xp(dx[d][i]), p.yp(dy[d][i]), sw, c)
+		}
+	}
 }
 
-// doellipse draws an ellipse
-func doellipse(doc *fc.Canvas, x, y, w, h float64, color string, opacity float64) {
+// outline strokes the closed outline of a shape through the points in x and y
+func (p fcdoc) outline(x, y []float64, s render.Style) {
+	if s.Stroke == "" {
+		return
+	}
+	s.Color = s.Stroke
+	p.segments(x, y, s)
 }
 
-// dopoly draws a polygon
-func dopoly(doc *fc.Canvas, xc, yc string, cw, ch float64, color string, opacity float64) {
+// Rect draws a rectangle; fc does not support gradients, so shapes are filled with their color instead
+func (p fcdoc) Rect(x, y, w, h float64, s render.Style) {
+	if !s.NoFill {
+		p.doc.Rect(p.xp(x+(w/2)), p.yp(y+(h/2)), p.wp(w), p.hp(h), p.color(s))
+	}
+	p.outline([]float64{x, x + w, x + w, x, x}, []float64{y, y, y + h, y + h, y}, s)
 }
 
-// dotext places text elements on the canvas according to type
-func dotext(doc *fc.Canvas, x, y, fs, wp, rotation, spacing float64, tdata, font, align, ttype, color string, opacity float64) {
-	td := strings.Split(tdata, "\n")
-	c := fc.ColorLookup(color)
-	if ttype == "code" {
-		font = "mono"
-		ch := float64(len(td)) * spacing * fs
-		bx := (x + (wp / 2))
-		by := (y - (ch / 2)) + (spacing * fs)
-		dorect(doc, bx, by, wp+fs, ch+fs, "rgb(240,240,240)", 100)
-	}
-	if ttype == "block" {
-		textwrap(doc, x, y, wp, fs, fs*spacing, tdata, c, font)
-	} else {
-		ls := spacing * fs
-		for _, t := range td {
-			showtext(doc, x, y, t, fs, c, font, align)
-			y -= ls
-		}
+// Ellipse draws a circle; fc does not support filled ellipses with unequal radii
+func (p fcdoc) Ellipse(x, y, w, h float64, s render.Style) {
+	if w == h && !s.NoFill {
+		p.doc.Circle(p.xp(x), p.yp(y), p.wp(w*2), p.color(s))
 	}
+	px, py := render.ArcPoints(x, y, w, h, 0, 360)
+	p.outline(px, py, s)
 }
 
-// whitespace determines if a rune is whitespace
-func whitespace(r rune) bool {
-	return r == ' ' || r == '\n' || r == '\t'
+// Arc approximates an arc with line segments
+func (p fcdoc) Arc(x, y, w, h, a1, a2 float64, s render.Style) {
+	px, py := render.ArcPoints(x, y, w, h, a1, a2)
+	p.segments(px, py, s)
 }
 
-// loadfont loads a font at the specified size
-func loadfont(doc *fc.Canvas, s string, size float64) {
+// Curve approximates a quadratic bezier curve with line segments
+func (p fcdoc) Curve(x1, y1, x2, y2, x3, y3 float64, s render.Style) {
+	px, py := render.CurvePoints(x1, y1, x2, y2, x3, y3)
+	p.segments(px, py, s)
 }
 
-// textwrap draws text at location, wrapping at the specified width
-func textwrap(doc *fc.Canvas, x, y, w, fs, leading float64, s string, color color.RGBA, font string) int {
-	var factor = 0.3
-	if font == "mono" {
-		factor = 1.0
-	}
-	nbreak := 0
-	loadfont(doc, font, fs)
-	wordspacing := doc.TextWidth("M", fs)
-	words := strings.FieldsFunc(s, whitespace)
-	xp := x
-	yp := y
-	edge := x + w
-	for _, s := range words {
-		tw := doc.TextWidth(s, fs)
-		doc.Text(xp, yp, fs, s, color)
-		xp += tw + (wordspacing * factor)
-		if xp > edge {
-			xp = x
-			yp -= leading
-			nbreak++
-		}
+// Line draws a line
+func (p fcdoc) Line(x1, y1, x2, y2 float64, s render.Style) {
+	p.segments([]float64{x1, x2}, []float64{y1, y2}, s)
+}
+
+// Polygon outlines a polygon; fc does not support filled polygons
+func (p fcdoc) Polygon(x, y []float64, s render.Style) {
+	p.outline(append(x[:len(x):len(x)], x[0]), append(y[:len(y):len(y)], y[0]), s)
+}
+
+// Polyline draws connected line segments
+func (p fcdoc) Polyline(x, y []float64, s render.Style) {
+	p.segments(x, y, s)
+}
+
+// Path outlines the subpaths of a path; fc does not support filled paths
+func (p fcdoc) Path(ops []render.PathOp, s render.Style) {
+	px, py := render.Subpaths(ops)
+	for i := range px {
+		p.outline(px[i], py[i], s)
 	}
-	return nbreak
 }
 
-// showtext places fully attributed text at the specified location
-func showtext(doc *fc.Canvas, x, y float64, s string, fs float64, color color.RGBA, font, align string) {
-	loadfont(doc, font, fs)
-	switch align {
+// Text places fully attributed text at the specified location
+func (p fcdoc) Text(x, y float64, s string, st render.Style) {
+	c := p.color(st)
+	fs := p.wp(st.Size)
+	switch st.Align {
 	case "center", "middle", "mid", "c":
-		doc.CText(x, y, fs, s, color)
+		p.doc.CText(p.xp(x), p.yp(y), fs, s, c)
 	case "right", "end", "e":
-		doc.EText(x, y, fs, s, color)
+		p.doc.EText(p.xp(x), p.yp(y), fs, s, c)
 	default:
-		doc.Text(x, y, fs, s, color)
+		p.doc.Text(p.xp(x), p.yp(y), fs, s, c)
 	}
 }
 
-// dolist places lists on the canvas
-func dolist(doc *fc.Canvas, cw, x, y, fs, lwidth, rotation, spacing float64, list []deck.ListItem, font, ltype, align, color string, opacity float64) {
-	if font == "" {
-		font = "sans"
-	}
-	c := fc.ColorLookup(color)
-	ls := listspacing * fs
-	for i, tl := range list {
-		loadfont(doc, font, fs)
-		if len(tl.Color) > 0 {
-			c = fc.ColorLookup(tl.Color)
-		}
-		switch ltype {
-		case "number":
-			showtext(doc, x, y, fmt.Sprintf("%d. ", i+1)+tl.ListText, fs, c, font, align)
-		case "bullet":
-			doc.Circle(x, y+fs/3, fs/2, c)
-			showtext(doc, x+fs, y, tl.ListText, fs, c, font, align)
-		case "center":
-			showtext(doc, x, y, tl.ListText, fs, c, font, align)
-		default:
-			showtext(doc, x, y, tl.ListText, fs, c, font, align)
-		}
-		y -= ls
-	}
+// TextWidth returns the width of text
+func (p fcdoc) TextWidth(s string, st render.Style) float64 {
+	return pct(p.doc.TextWidth(s, p.wp(st.Size)), p.cw) / p.scale
 }
 
-// showslide shows a slide
-func showslide(doc *fc.Canvas, d *deck.Deck, n int) {
-	if n < 0 || n > len(d.Slide)-1 {
-		return
-	}
-	cw := float64(d.Canvas.Width)
-	ch := float64(d.Canvas.Height)
-	slide := d.Slide[n]
-	// set default background
-	if slide.Bg == "" {
-		slide.Bg = "white"
-	}
-	doc.CornerRect(0, 100, cw, ch, fc.ColorLookup(slide.Bg))
+// Image places an image centered at (x,y)
+func (p fcdoc) Image(x, y, w, h float64, name string, s render.Style) {
+	p.doc.Image(p.xp(x), p.yp(y), int(w*p.scale), int(h*p.scale), name)
+}
 
-	if slide.GradPercent <= 0 || slide.GradPercent > 100 {
-		slide.GradPercent = 100
-	}
-	// set gradient background, if specified. You need both colors
-	if len(slide.Gradcolor1) > 0 && len(slide.Gradcolor2) > 0 {
-		gradient(doc, cw, ch, slide.Gradcolor1, slide.Gradcolor2, slide.GradPercent)
-	}
-	// set the default foreground
-	if slide.Fg == "" {
-		slide.Fg = "black"
-	}
-	// for every image on the slide...
-	for _, im := range slide.Image {
-		iw, ih := im.Width, im.Height
-		// scale the image by the specified percentage
-		if im.Scale > 0 {
-			iw = int(float64(iw) * (im.Scale / 100))
-			ih = int(float64(ih) * (im.Scale / 100))
-		}
-		// scale the image to fit the canvas width
-		if im.Autoscale == "on" && iw < d.Canvas.Width {
-			ih = int((float64(d.Canvas.Width) / float64(iw)) * float64(ih))
-			iw = d.Canvas.Width
-		}
+// Rotate is not supported by fc
+func (p fcdoc) Rotate(x, y, angle float64) {
+}
 
-		// scale the image to a percentage of the canvas width
-		if im.Height == 0 && im.Width > 0 {
-			nw, nh := imageInfo(im.Name)
-			if nh > 0 {
-				var fw, fh float64
-				imscale := (float64(iw) / 100) * cw
-				fw = imscale
-				fh = imscale / (float64(nw) / float64(nh))
-				iw = int(fw)
-				ih = int(fh)
-			}
-		}
-		doc.Image(im.Xp, im.Yp, iw, ih, im.Name)
-		if len(im.Caption) > 0 {
-			capsize := 1.5
-			if im.Font == "" {
-				im.Font = "sans"
-			}
-			if im.Color == "" {
-				im.Color = slide.Fg
-			}
-			if im.Align == "" {
-				im.Align = "center"
-			}
-			var cx float64
-			switch im.Align {
-			case "center", "c", "mid":
-				cx = im.Xp
-			case "end", "e", "right":
-				cx = im.Xp + pct(float64(iw/2), cw)
-			default:
-				cx = im.Xp - pct(float64(iw/2), cw)
-			}
-			cy := im.Yp - ((float64(ih/2) / ch) * 100) - (capsize * 2)
-			showtext(doc, cx, cy, im.Caption, capsize, fc.ColorLookup(im.Color), im.Font, im.Align)
-		}
-	}
-	// every graphic on the slide
-	const defaultColor = "rgb(127,127,127)"
-	// rect
-	for _, rect := range slide.Rect {
-		if rect.Color == "" {
-			rect.Color = defaultColor
-		}
-		if rect.Hr == 100 {
-			c := fc.ColorLookup(rect.Color)
-			c.A = setop(rect.Opacity)
-			doc.Rect(rect.Xp, rect.Yp, rect.Wp, rect.Wp*(cw/ch), c)
-		} else {
-			dorect(doc, rect.Xp, rect.Yp, rect.Wp, rect.Hp, rect.Color, rect.Opacity)
-		}
-	}
-	// ellipse
-	for _, ellipse := range slide.Ellipse {
-		if ellipse.Color == "" {
-			ellipse.Color = defaultColor
-		}
-		if ellipse.Hr == 100 {
-			c := fc.ColorLookup(ellipse.Color)
-			c.A = setop(ellipse.Opacity)
-			doc.Circle(ellipse.Xp, ellipse.Yp, ellipse.Wp, c)
-		} else {
-			doellipse(doc, ellipse.Xp, ellipse.Yp, ellipse.Wp, ellipse.Hp, ellipse.Color, ellipse.Opacity)
-		}
-	}
-	// curve
-	for _, curve := range slide.Curve {
-		if curve.Color == "" {
-			curve.Color = defaultColor
-		}
-		if curve.Sp == 0 {
-			curve.Sp = 2.0
-		}
-		docurve(doc, curve.Xp1, curve.Yp1, curve.Xp2, curve.Yp2, curve.Xp3, curve.Yp3, curve.Sp, curve.Color, curve.Opacity)
-	}
-	// arc
-	for _, arc := range slide.Arc {
-		if arc.Color == "" {
-			arc.Color = defaultColor
-		}
-		w := arc.Wp
-		h := arc.Hp
-		if arc.Sp == 0 {
-			arc.Sp = 2.0
-		}
-		doarc(doc, arc.Xp, arc.Yp, w/2, h/2, arc.A1, arc.A2, arc.Sp, arc.Color, arc.Opacity)
-	}
-	// line
-	for _, line := range slide.Line {
-		if line.Color == "" {
-			line.Color = defaultColor
-		}
-		if line.Sp == 0 {
-			line.Sp = 0.2
-		}
-		doline(doc, line.Xp1, line.Yp1, line.Xp2, line.Yp2, line.Sp, line.Color, line.Opacity)
-	}
-	// polygon
-	for _, poly := range slide.Polygon {
-		if poly.Color == "" {
-			poly.Color = defaultColor
-		}
-		dopoly(doc, poly.XC, poly.YC, cw, ch, poly.Color, poly.Opacity)
-	}
+// EndRotate is not supported by fc
+func (p fcdoc) EndRotate() {
+}
 
-	// for every text element...
-	var tdata string
-	for _, t := range slide.Text {
-		if t.Color == "" {
-			t.Color = slide.Fg
-		}
-		if t.Font == "" {
-			t.Font = "sans"
-		}
-		if t.File != "" {
-			tdata = includefile(t.File)
-		} else {
-			tdata = t.Tdata
-		}
-		if t.Lp == 0 {
-			t.Lp = linespacing
-		}
-		dotext(doc, t.Xp, t.Yp, t.Sp, t.Wp, t.Rotation, t.Lp*1.2, tdata, t.Font, t.Align, t.Type, t.Color, t.Opacity)
-	}
-	// for every list element...
-	for _, l := range slide.List {
-		if l.Color == "" {
-			l.Color = slide.Fg
-		}
-		if l.Lp == 0 {
-			l.Lp = listspacing
-		}
-		if l.Wp == 0 {
-			l.Wp = listwrap
-		}
-		dolist(doc, cw, l.Xp, l.Yp, l.Sp, l.Wp, l.Rotation, l.Lp, l.Li, l.Font, l.Type, l.Align, l.Color, l.Opacity)
+// showslide shows a slide, at the current build step, updating the presenter window
+func showslide(doc *fc.Canvas, d *deck.Deck, n int) {
+	if n < 0 || n > len(d.Slide)-1 {
+		return
 	}
+	doc.Container.Objects = nil
+	render.Slide(slidedoc(doc, d), *d, n, render.Options{Step: buildstep})
 	doc.Container.Refresh()
-
+	if presenter != nil {
+		present(presenter, d, n)
+	}
 }
 
 // hup processes the hangup (SIGHUP) signal
-// re-read the input, adjust the number of slides, show the first slide
+// re-read the input, show the first slide
 func hup(sigch chan os.Signal, filename string, c *fc.Canvas, d *deck.Deck, w, h int, n *int) {
 	for range sigch {
 		newdeck, err := readDeck(filename, w, h)
@@ -473,11 +278,13 @@ func hup(sigch chan os.Signal, filename string, c *fc.Canvas, d *deck.Deck, w, h
 			fmt.Fprintf(os.Stderr, "%v\n", err)
 			return
 		}
-		nd := len(newdeck.Slide)
-		d.Slide = make([]deck.Slide, nd)
-		copy(d.Slide, newdeck.Slide)
-		*n = nd - 1
-		showslide(c, d, 0)
+		do(func() {
+			nd := len(newdeck.Slide)
+			d.Slide = make([]deck.Slide, nd)
+			copy(d.Slide, newdeck.Slide)
+			*n, buildstep = 0, 1
+			showslide(c, d, 0)
+		})
 	}
 }
 
@@ -489,66 +296,90 @@ func readDeck(filename string, w, h int) (deck.Deck, error) {
 	return d, err
 }
 
-// back shows the previous slide
-func back(c *fc.Canvas, d *deck.Deck, n *int, limit int) {
+// steps returns the number of build steps of slide n
+func steps(d *deck.Deck, n int) int {
+	if n < 0 || n > len(d.Slide)-1 {
+		return 1
+	}
+	return deck.Steps(d.Slide[n])
+}
+
+// pausetime returns how long a build step of slide n shows while playing:
+// its share of the slide duration, or else the pause
+func pausetime(d *deck.Deck, n int, pause time.Duration) time.Duration {
+	if n >= 0 && n < len(d.Slide) {
+		if t := duration(d.Slide[n]); t > 0 {
+			return t / time.Duration(steps(d, n))
+		}
+	}
+	return pause
+}
+
+// back shows the previous build step, or the previous slide, fully built
+func back(c *fc.Canvas, d *deck.Deck, n *int) {
+	if buildstep > 1 {
+		buildstep--
+		showslide(c, d, *n)
+		return
+	}
 	*n--
 	if *n < 0 {
-		*n = limit
+		*n = len(d.Slide) - 1
 	}
+	buildstep = steps(d, *n)
 	showslide(c, d, *n)
 }
 
-// forward shows the next slide
-func forward(c *fc.Canvas, d *deck.Deck, n *int, limit int) {
+// forward shows the next build step, or the next slide
+func forward(c *fc.Canvas, d *deck.Deck, n *int) {
+	if buildstep < steps(d, *n) {
+		buildstep++
+		showslide(c, d, *n)
+		return
+	}
 	*n++
-	if *n > limit {
+	if *n > len(d.Slide)-1 {
 		*n = 0
 	}
+	buildstep = 1
 	showslide(c, d, *n)
 }
 
 // reload reloads the content and shows the first slide
-func reload(filename string, c *fc.Canvas, w, h, n int) (deck.Deck, int) {
+func reload(filename string, c *fc.Canvas, w, h, n int) deck.Deck {
 	d, err := readDeck(filename, w, h)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
-		return d, 0
+		return d
 	}
 	showslide(c, &d, n)
-	return d, len(d.Slide) - 1
+	return d
 }
 
 // gridtoggle toggles a grid overlay
 func gridtoggle(c *fc.Canvas, size float64, d *deck.Deck, slidenumber int) {
 	if gridstate {
-		grid(c, 100, 100, d.Slide[slidenumber].Fg, size)
+		fg := d.Slide[slidenumber].Fg
+		if fg == "" {
+			fg = "black"
+		}
+		render.Grid(slidedoc(c, d), float64(d.Canvas.Width), float64(d.Canvas.Height), fg, size)
+		c.Container.Refresh()
 	} else {
 		showslide(c, d, slidenumber)
 	}
 	gridstate = !gridstate
 }
 
-// imageinfo returns the dimensions of an image
-func imageInfo(s string) (int, int) {
-	f, err := os.Open(s)
-	defer f.Close()
-	if err != nil {
-		return 0, 0
-	}
-	im, _, err := image.DecodeConfig(f)
-	if err != nil {
-		return 0, 0
-	}
-	return im.Width, im.Height
-}
-
 func main() {
 	var (
-		title    = flag.String("title", "", "slide title")
-		pagesize = flag.String("pagesize", "Letter", "pagesize: w,h, or one of: Letter, Legal, Tabloid, A3, A4, A5, ArchA, 4R, Index, Widescreen")
-		sans     = flag.String("sans", "", "default font")
-		initpage = flag.Int("page", 1, "initial page")
-		gp       = flag.Float64("grid", 5, "grid percent")
+		title      = flag.String("title", "", "slide title")
+		pagesize   = flag.String("pagesize", "Letter", "pagesize: w,h, or one of: Letter, Legal, Tabloid, A3, A4, A5, ArchA, 4R, Index, Widescreen")
+		sans       = flag.String("sans", "", "default font")
+		initpage   = flag.Int("page", 1, "initial page")
+		gp         = flag.Float64("grid", 5, "grid percent")
+		presenting = flag.Bool("presenter", false, "show a presenter window, with notes, times and the next slide")
+		loop       = flag.Duration("loop", 0, "play the slides in a loop, pausing the specified duration on slides without their own (5s if played later)")
 	)
 	flag.Parse()
 
@@ -577,60 +408,109 @@ func main() {
 		os.Exit(1)
 	}
 	// set initial values
-	nslides := len(d.Slide) - 1
-	if *initpage > nslides+1 || *initpage < 1 {
+	if *initpage > len(d.Slide) || *initpage < 1 {
 		*initpage = 1
 	}
 	canvas := &c
 	slidenumber := *initpage - 1
+	if *presenting {
+		presenter = newpresenter(width, height)
+	}
 	showslide(canvas, &d, slidenumber)
 
 	gridstate = true
 	sigch := make(chan os.Signal, 1)
 	signal.Notify(sigch, syscall.SIGHUP)
-	go hup(sigch, filename, canvas, &d, width, height, &nslides)
+	go hup(sigch, filename, canvas, &d, width, height, &slidenumber)
 
-	// Define keyboard shortcuts (back, forward, reload, grid, home, end, quit)
-	canvas.Window.Canvas().SetOnTypedKey(func(k *fyne.KeyEvent) {
+	// Define keyboard shortcuts (back, forward, reload, grid, home, end, restart the clock, play, quit);
+	// other keys pause play
+	key := func(k *fyne.KeyEvent) {
+		if k.Name != fyne.KeyP {
+			playing = false
+		}
 		switch k.Name {
 		case fyne.KeyRight, fyne.KeyDown, fyne.KeySpace, fyne.KeyEqual, fyne.KeyReturn, fyne.KeyPageDown:
-			forward(canvas, &d, &slidenumber, nslides)
+			forward(canvas, &d, &slidenumber)
 
 		case fyne.KeyLeft, fyne.KeyUp, fyne.KeyMinus, fyne.KeyBackspace, fyne.KeyPageUp:
-			back(canvas, &d, &slidenumber, nslides)
+			back(canvas, &d, &slidenumber)
 
 		case fyne.KeyR:
-			d, nslides = reload(filename, canvas, width, height, slidenumber)
+			d = reload(filename, canvas, width, height, slidenumber)
 
 		case fyne.KeyG:
 			gridtoggle(canvas, *gp, &d, slidenumber)
 
 		case fyne.KeyHome:
-			slidenumber = 0
+			slidenumber, buildstep = 0, 1
 			showslide(canvas, &d, slidenumber)
 
 		case fyne.KeyEnd:
-			slidenumber = nslides
+			slidenumber, buildstep = len(d.Slide)-1, 1
+			showslide(canvas, &d, slidenumber)
+
+		case fyne.KeyP:
+			playing = !playing
+
+		case fyne.KeyT:
+			restart()
 			showslide(canvas, &d, slidenumber)
 
 		case fyne.KeyQ, fyne.KeyEscape:
 			os.Exit(0)
 		}
-	})
+	}
+	keys := func(k *fyne.KeyEvent) { do(func() { key(k) }) }
+	canvas.Window.Canvas().SetOnTypedKey(keys)
 
-	// define the toolbar (back, forward, reload, grid)
+	// define the toolbar (back, forward, reload, first, last, grid, play)
 	toolbar := widget.NewToolbar(
-		widget.NewToolbarAction(theme.NavigateBackIcon(), func() { back(canvas, &d, &slidenumber, nslides) }),
-		widget.NewToolbarAction(theme.NavigateNextIcon(), func() { forward(canvas, &d, &slidenumber, nslides) }),
-		widget.NewToolbarAction(theme.MediaReplayIcon(), func() { d, nslides = reload(filename, canvas, width, height, slidenumber) }),
-		widget.NewToolbarAction(theme.MediaSkipPreviousIcon(), func() { slidenumber = 0; showslide(canvas, &d, slidenumber) }),
-		widget.NewToolbarAction(theme.MediaSkipNextIcon(), func() { slidenumber = nslides; showslide(canvas, &d, slidenumber) }),
-		widget.NewToolbarAction(theme.VisibilityIcon(), func() { gridtoggle(canvas, *gp, &d, slidenumber) }),
+		widget.NewToolbarAction(theme.NavigateBackIcon(), func() { do(func() { back(canvas, &d, &slidenumber) }) }),
+		widget.NewToolbarAction(theme.NavigateNextIcon(), func() { do(func() { forward(canvas, &d, &slidenumber) }) }),
+		widget.NewToolbarAction(theme.MediaReplayIcon(), func() { do(func() { d = reload(filename, canvas, width, height, slidenumber) }) }),
+		widget.NewToolbarAction(theme.MediaSkipPreviousIcon(), func() { do(func() { slidenumber, buildstep = 0, 1; showslide(canvas, &d, slidenumber) }) }),
+		widget.NewToolbarAction(theme.MediaSkipNextIcon(), func() { do(func() { slidenumber, buildstep = len(d.Slide)-1, 1; showslide(canvas, &d, slidenumber) }) }),
+		widget.NewToolbarAction(theme.VisibilityIcon(), func() { do(func() { gridtoggle(canvas, *gp, &d, slidenumber) }) }),
+		widget.NewToolbarAction(theme.MediaPlayIcon(), func() { do(func() { playing = !playing }) }),
 	)
 	// add the content
 	w.SetContent(fyne.NewContainerWithLayout(layout.NewBorderLayout(toolbar, nil, nil, nil), toolbar, c.Container))
 	w.Resize(fyne.NewSize(float32(width), float32(height)+toolbar.Size().Height))
 
+	// the presenter window follows the slides, and keeps time
+	var ticks <-chan time.Time
+	if presenter != nil {
+		presenter.Window.Canvas().SetOnTypedKey(keys)
+		presenter.Window.Show()
+		ticks = time.Tick(time.Second)
+	}
+
+	// the event loop: run the events, play, advancing after each build step's share of the slide
+	// duration, or the pause, timed from the last event; and update the presenter times every second
+	pause := *loop
+	if pause <= 0 {
+		pause = defaultpause
+	}
+	playing = *loop > 0
+	player := time.NewTimer(pausetime(&d, slidenumber, pause))
+	go func() {
+		for {
+			select {
+			case f := <-events:
+				f()
+			case <-player.C:
+				if playing {
+					forward(canvas, &d, &slidenumber)
+				}
+			case <-ticks:
+				clocks(presenter, &d, slidenumber)
+				continue
+			}
+			player.Reset(pausetime(&d, slidenumber, pause))
+		}
+	}()
+
 	// run it!
 	w.ShowAndRun()
 }
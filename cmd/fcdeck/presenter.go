@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"github.com/ajstarks/deck"
+	"github.com/ajstarks/deck/render"
+	"github.com/ajstarks/fc"
+)
+
+// presenter is the presenter window, if shown
+var presenter *fc.Canvas
+
+// the talk began at talkstart, and the slide shown in the presenter window at slidestart;
+// the times follow the first timemark objects of the window
+var (
+	talkstart, slidestart time.Time
+	presented             = -1
+	timemark              int
+)
+
+// presenter layout, in percentages of the window
+const (
+	notesize = 1.8 // size of note text
+	infosize = 2.2 // size of the slide number and times
+	notewrap = 96  // width of note text
+)
+
+// newpresenter makes the presenter window, the size of the slides, and starts the clock
+func newpresenter(w, h int) *fc.Canvas {
+	win := fyne.CurrentApp().NewWindow("Presenter")
+	c := &fc.Canvas{Window: win, Container: container.NewWithoutLayout(), Width: float64(w), Height: float64(h)}
+	win.SetContent(c.Container)
+	win.Resize(fyne.NewSize(float32(w), float32(h)))
+	talkstart = time.Now()
+	return c
+}
+
+// restart restarts the talk and slide clocks
+func restart() {
+	talkstart, slidestart = time.Now(), time.Now()
+}
+
+// duration returns the time budgeted for a slide, zero if not specified
+func duration(s deck.Slide) time.Duration {
+	t, err := time.ParseDuration(s.Duration)
+	if err != nil {
+		return 0
+	}
+	return t
+}
+
+// clock formats a duration as minutes and seconds
+func clock(t time.Duration) string {
+	sign := ""
+	if t < 0 {
+		sign, t = "-", -t
+	}
+	t = t.Round(time.Second)
+	return fmt.Sprintf("%s%d:%02d", sign, int(t.Minutes()), int(t.Seconds())%60)
+}
+
+// wrap breaks text into lines no wider than w at the font size (both percentages of the canvas width);
+// blank lines separate paragraphs
+func wrap(c *fc.Canvas, s string, size, w float64) []string {
+	var lines []string
+	var line string
+	for _, src := range strings.Split(strings.TrimSpace(s), "\n") {
+		words := strings.Fields(src)
+		if len(words) == 0 {
+			if line != "" {
+				lines = append(lines, line, "")
+				line = ""
+			}
+			continue
+		}
+		for _, word := range words {
+			switch {
+			case line == "":
+				line = word
+			case c.TextWidth(line+" "+word, size) > w:
+				lines = append(lines, line)
+				line = word
+			default:
+				line += " " + word
+			}
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// present shows slide n in the presenter window, at the current build step, beside a preview
+// of what comes next, with its notes and times
+func present(c *fc.Canvas, d *deck.Deck, n int) {
+	if n < 0 || n > len(d.Slide)-1 {
+		return
+	}
+	if n != presented {
+		presented, slidestart = n, time.Now()
+	}
+	c.Container.Objects = nil
+	c.Rect(50, 50, 100, 100, fc.ColorLookup("black"))
+	white, gray := fc.ColorLookup("white"), fc.ColorLookup("gray")
+
+	// the slide, and the next build step or slide
+	doc := slidedoc(c, d)
+	doc.left, doc.bottom, doc.scale = 2, 36, 0.6
+	render.Slide(doc, *d, n, render.Options{Step: buildstep})
+	next, step := n, buildstep+1
+	if buildstep >= steps(d, n) {
+		next, step = n+1, 1
+	}
+	if next < len(d.Slide) {
+		doc.left, doc.bottom, doc.scale = 66, 64, 0.32
+		render.Slide(doc, *d, next, render.Options{Step: step})
+	} else {
+		c.CText(82, 80, infosize, "End", gray)
+	}
+
+	// notes, as many lines as fit
+	y := 30.0
+	for _, line := range wrap(c, d.Slide[n].Note, notesize, notewrap) {
+		if y < 2 {
+			break
+		}
+		c.Text(2, y, notesize, line, white)
+		y -= notesize * 1.6 * c.Width / c.Height
+	}
+	timemark = len(c.Container.Objects)
+	clocks(c, d, n)
+}
+
+// clocks shows the slide number, and the time spent and remaining, budgeted by the slide durations,
+// in the presenter window showing slide n, replacing the times shown before
+func clocks(c *fc.Canvas, d *deck.Deck, n int) {
+	if n != presented || n > len(d.Slide)-1 || timemark > len(c.Container.Objects) {
+		return
+	}
+	c.Container.Objects = c.Container.Objects[:timemark]
+	white, gray, red := fc.ColorLookup("white"), fc.ColorLookup("gray"), fc.ColorLookup("red")
+	var budget time.Duration
+	for _, s := range d.Slide {
+		budget += duration(s)
+	}
+	now := time.Now()
+	elapsed, onslide := now.Sub(talkstart), now.Sub(slidestart)
+	c.Text(66, 56, infosize, fmt.Sprintf("Slide %d of %d", n+1, len(d.Slide)), white)
+	c.Text(66, 50, infosize, "Elapsed "+clock(elapsed), white)
+	if budget > 0 {
+		left, tc := budget-elapsed, white
+		if left < 0 {
+			tc = red
+		}
+		c.Text(66, 44, infosize, "Remaining "+clock(left), tc)
+	}
+	if t := duration(d.Slide[n]); t > 0 {
+		tc := gray
+		if onslide > t {
+			tc = red
+		}
+		c.Text(66, 38, infosize, fmt.Sprintf("This slide %s of %s", clock(onslide), clock(t)), tc)
+	}
+	c.Container.Refresh()
+}
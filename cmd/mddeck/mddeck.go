@@ -0,0 +1,437 @@
+// mddeck: convert Markdown to deck markup
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ajstarks/deck/generate"
+	"github.com/ajstarks/deck/render"
+)
+
+// layout, in percentages
+const (
+	left      = 5.0
+	margin    = 90.0
+	top       = 90.0
+	bottom    = 5.0
+	titlesize = 4.0
+	headsize  = 3.0
+	subsize   = 2.5
+	textsize  = 2.0
+	codesize  = 1.5
+	capsize   = 2.0 // size of image captions, as drawn by the renderers
+	spacing   = 1.4 // text line spacing
+	listspace = 1.8 // list item spacing
+)
+
+var (
+	numbered = regexp.MustCompile(`^\s*\d+[.)]\s+`)
+	bulleted = regexp.MustCompile(`^\s*[-*+]\s+`)
+	imageref = regexp.MustCompile(`^!\[(.*)\]\((\S+)(?:\s+"(.*)")?\)$`)
+	heading  = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+	rule     = regexp.MustCompile(`^(-{3,}|\*{3,}|_{3,})$`)
+)
+
+// inline emphasis: **strong** or __strong__, and *emphasis* or _emphasis_,
+// with underscores only outside of words
+var (
+	strong = []*regexp.Regexp{
+		regexp.MustCompile(`()\*\*([^*\s](?:[^*]*[^*\s])?)\*\*()`),
+		regexp.MustCompile(`(^|\W)__([^_\s](?:[^_]*[^_\s])?)__(\W|$)`),
+	}
+	emphasis = []*regexp.Regexp{
+		regexp.MustCompile(`()\*([^*\s](?:[^*]*[^*\s])?)\*()`),
+		regexp.MustCompile(`(^|\W)_([^_\s](?:[^_]*[^_\s])?)_(\W|$)`),
+	}
+)
+
+// captionfmt is the markup of a captioned image, which generate does not make
+const captionfmt = `<image xp="%.2f" yp="%.2f" width="%d" height="%d" name="%s" caption="%s"/>`
+
+var xmlmap = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;")
+
+var attrmap = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	`"`, "&quot;")
+
+func xmlesc(s string) string {
+	return xmlmap.Replace(s)
+}
+
+// spans converts the inline emphasis and `code` of Markdown text
+// to the inline elements of deck markup (<b>, <i> and <span font="mono">)
+func spans(s string) string {
+	return inline(s, "<b>$2</b>", "<i>$2</i>", `<span font="mono">`, "</span>", xmlesc)
+}
+
+// plain removes the inline emphasis and `code` marks of Markdown text, for attributes
+func plain(s string) string {
+	return inline(s, "$2", "$2", "", "", func(s string) string { return s })
+}
+
+// inline converts the inline marks of Markdown text; the text of code spans is kept as is
+func inline(s, b, i, code, endcode string, esc func(string) string) string {
+	var out strings.Builder
+	for k, part := range codespans(s) {
+		part = esc(part)
+		if k%2 == 1 {
+			out.WriteString(code + part + endcode)
+			continue
+		}
+		for _, re := range strong {
+			part = re.ReplaceAllString(part, "${1}"+b+"${3}")
+		}
+		for _, re := range emphasis {
+			part = re.ReplaceAllString(part, "${1}"+i+"${3}")
+		}
+		out.WriteString(part)
+	}
+	return out.String()
+}
+
+// codespans splits text into the parts outside and inside code spans;
+// an unmatched backquote is taken literally
+func codespans(s string) []string {
+	parts := strings.Split(s, "`")
+	if n := len(parts); n%2 == 0 {
+		parts = append(parts[:n-2], parts[n-2]+"`"+parts[n-1])
+	}
+	return parts
+}
+
+// frontmatter describes the deck
+type frontmatter struct {
+	title, creator, date string
+	width, height        int
+}
+
+// converter makes slides from Markdown blocks
+type converter struct {
+	deck      *generate.Deck
+	w         io.Writer // destination of markup not made by deck
+	cw, ch    int
+	y         float64 // current vertical position
+	inslide   bool
+	slides    int // number of slides begun
+	para      []string
+	items     []string
+	listtype  string
+	incode    bool
+	code      []string
+	codefence string
+}
+
+// readfront reads YAML-style front matter (key: value pairs between --- lines)
+func readfront(lines []string, fm *frontmatter) []string {
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return lines
+	}
+	for i := 1; i < len(lines); i++ {
+		l := strings.TrimSpace(lines[i])
+		if l == "---" || l == "..." {
+			return lines[i+1:]
+		}
+		kv := strings.SplitN(l, ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(kv[0]))
+		value := strings.Trim(strings.TrimSpace(kv[1]), `"'`)
+		switch key {
+		case "title":
+			fm.title = value
+		case "creator", "author":
+			fm.creator = value
+		case "date":
+			fm.date = value
+		case "width":
+			fm.width, _ = strconv.Atoi(value)
+		case "height":
+			fm.height, _ = strconv.Atoi(value)
+		case "canvas", "size":
+			// canvas: 1024x768
+			if wh := strings.Split(value, "x"); len(wh) == 2 {
+				fm.width, _ = strconv.Atoi(strings.TrimSpace(wh[0]))
+				fm.height, _ = strconv.Atoi(strings.TrimSpace(wh[1]))
+			}
+		}
+	}
+	// no closing line: not front matter
+	return lines
+}
+
+// metadata writes the deck metadata elements
+func metadata(w io.Writer, fm frontmatter) {
+	for _, m := range [][2]string{{"title", fm.title}, {"creator", fm.creator}, {"date", fm.date}} {
+		if m[1] != "" {
+			fmt.Fprintf(w, "<%s>%s</%s>\n", m[0], xmlesc(m[1]), m[0])
+		}
+	}
+}
+
+// height converts a text size (percentage of the canvas width)
+// to a percentage of the canvas height
+func (c *converter) height(size float64) float64 {
+	return size * float64(c.cw) / float64(c.ch)
+}
+
+// startslide begins a slide, if one is not in progress
+func (c *converter) startslide() {
+	if !c.inslide {
+		c.deck.StartSlide()
+		c.inslide = true
+		c.slides++
+		c.y = top
+	}
+}
+
+// room makes room for content h high (a percentage of the canvas height):
+// content that does not fit below what is already on the slide continues on a new one,
+// and content too high for any slide is reported
+func (c *converter) room(h float64) {
+	if c.inslide && c.y < top && c.y-h < bottom {
+		c.deck.EndSlide()
+		c.inslide = false
+	}
+	c.startslide()
+	if c.y-h < bottom {
+		fmt.Fprintf(os.Stderr, "mddeck: slide %d: content runs below the bottom of the slide\n", c.slides)
+	}
+}
+
+// lines estimates the number of lines of text of the specified size wrapped at the margin
+func lines(s string, size float64) float64 {
+	perline := margin / (size * 0.55)
+	return math.Max(1, math.Ceil(float64(len([]rune(plain(s))))/perline))
+}
+
+// endslide ends the current slide
+func (c *converter) endslide() {
+	c.flush()
+	if c.inslide {
+		c.deck.EndSlide()
+		c.inslide = false
+	}
+}
+
+// flush writes any pending paragraph or list
+func (c *converter) flush() {
+	if len(c.para) > 0 {
+		s := strings.Join(c.para, " ")
+		h := lines(s, textsize) * c.height(textsize) * spacing
+		c.room(h)
+		c.deck.TextBlock(left, c.y, spans(s), "sans", textsize, margin, "")
+		c.y -= h + c.height(textsize)
+		c.para = nil
+	}
+	// lists continue on new slides, with as many items as fit on each;
+	// numbered lists continue with the numbers written out
+	ltype, n := c.listtype, 0
+	for len(c.items) > 0 {
+		c.room(lines(c.items[0], textsize) * c.height(textsize) * listspace)
+		var items []string
+		var h float64
+		for _, item := range c.items {
+			ih := lines(item, textsize) * c.height(textsize) * listspace
+			if len(items) > 0 && c.y-(h+ih) < bottom {
+				break
+			}
+			if n > 0 && ltype == "number" {
+				item = fmt.Sprintf("%d. %s", n+len(items)+1, item)
+			}
+			items, h = append(items, spans(item)), h+ih
+		}
+		t := ltype
+		if n > 0 && ltype == "number" {
+			t = "plain"
+		}
+		c.deck.List(left, c.y, textsize, listspace, margin, items, t, "sans", "")
+		c.y -= h + c.height(textsize)
+		c.items, n = c.items[len(items):], n+len(items)
+	}
+}
+
+// heading places a heading; level 1 and 2 headings begin a new slide
+func (c *converter) heading(level int, s string) {
+	c.flush()
+	size := subsize
+	if level <= 2 {
+		c.endslide()
+		size = headsize
+		if level == 1 {
+			size = titlesize
+		}
+	}
+	c.room(c.height(size) * 2)
+	c.deck.Text(left, c.y, spans(s), "sans", size, "")
+	c.y -= c.height(size) * 2
+}
+
+// image places an image centered horizontally, with an optional caption below it;
+// images are reduced to fit the space left on the slide, or on a new one, if that is larger
+func (c *converter) image(name, caption string) {
+	c.flush()
+	w, h := render.ImageInfo(name)
+	if w == 0 || h == 0 {
+		fmt.Fprintf(os.Stderr, "mddeck: unable to read image %q\n", name)
+		w, h = c.cw/2, c.ch/2
+	}
+	hp := float64(h) / float64(c.ch) * 100
+	var caph float64
+	if caption != "" {
+		caph = c.height(capsize) * 2.5
+	}
+	if c.y-hp-caph < bottom && c.y-bottom < (top-bottom)/2 {
+		c.room(top)
+	}
+	c.startslide()
+	if avail := c.y - bottom - caph; hp > avail && avail > 0 {
+		scale := avail / hp
+		w, h = int(float64(w)*scale), int(float64(h)*scale)
+		hp = avail
+	}
+	if caption != "" {
+		fmt.Fprintf(c.w, captionfmt, 50.0, c.y-hp/2, w, h, attrmap.Replace(name), attrmap.Replace(plain(caption)))
+	} else {
+		c.deck.Image(50, c.y-hp/2, w, h, name, "")
+	}
+	c.y -= hp + caph
+}
+
+// line processes a line of Markdown
+func (c *converter) line(l string) {
+	t := strings.TrimSpace(l)
+	// fenced code
+	if c.incode {
+		if t == c.codefence {
+			// code continues on new slides, with as many lines as fit on each
+			lh := c.height(codesize) * spacing
+			for len(c.code) > 0 {
+				c.room(2 * lh)
+				n := max(1, min(len(c.code), int((c.y-bottom)/lh)-1))
+				c.deck.Code(left, c.y, xmlesc(strings.Join(c.code[:n], "\n")), codesize, margin, "")
+				c.y -= float64(n+1)*lh + c.height(textsize)
+				c.code = c.code[n:]
+			}
+			c.incode = false
+			return
+		}
+		c.code = append(c.code, strings.Replace(l, "\t", "    ", -1))
+		return
+	}
+	if strings.HasPrefix(t, "```") || strings.HasPrefix(t, "~~~") {
+		c.flush()
+		c.incode = true
+		c.codefence = t[:3]
+		return
+	}
+	if t == "" {
+		c.flush()
+		return
+	}
+	if rule.MatchString(t) {
+		c.endslide()
+		return
+	}
+	if m := heading.FindStringSubmatch(t); m != nil {
+		c.heading(len(m[1]), m[2])
+		return
+	}
+	if m := imageref.FindStringSubmatch(t); m != nil {
+		caption := m[1]
+		if m[3] != "" {
+			caption = strings.ReplaceAll(m[3], `\"`, `"`)
+		}
+		c.image(m[2], caption)
+		return
+	}
+	for _, lt := range []struct {
+		re    *regexp.Regexp
+		ltype string
+	}{{bulleted, "bullet"}, {numbered, "number"}} {
+		if loc := lt.re.FindStringIndex(l); loc != nil {
+			if len(c.para) > 0 || (len(c.items) > 0 && c.listtype != lt.ltype) {
+				c.flush()
+			}
+			c.listtype = lt.ltype
+			c.items = append(c.items, strings.TrimSpace(l[loc[1]:]))
+			return
+		}
+	}
+	// continuation of a list item
+	if len(c.items) > 0 && (strings.HasPrefix(l, " ") || strings.HasPrefix(l, "\t")) {
+		c.items[len(c.items)-1] += " " + t
+		return
+	}
+	if len(c.items) > 0 {
+		c.flush()
+	}
+	c.para = append(c.para, t)
+}
+
+// convert reads Markdown, writing deck markup
+func convert(r io.Reader, w io.Writer, cw, ch int) error {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	fm := frontmatter{width: cw, height: ch}
+	lines = readfront(lines, &fm)
+	if fm.width <= 0 || fm.height <= 0 {
+		fm.width, fm.height = cw, ch
+	}
+	c := &converter{deck: generate.NewSlides(w, fm.width, fm.height), w: w, cw: fm.width, ch: fm.height}
+	c.deck.StartDeck()
+	metadata(w, fm)
+	for _, l := range lines {
+		c.line(l)
+	}
+	if c.incode {
+		c.line(c.codefence)
+	}
+	c.endslide()
+	c.deck.EndDeck()
+	return nil
+}
+
+func main() {
+	var width = flag.Int("w", 792, "canvas width")
+	var height = flag.Int("h", 612, "canvas height")
+	flag.Parse()
+	var r io.Reader = os.Stdin
+	switch flag.NArg() {
+	case 0:
+	case 1:
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "mddeck: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		r = f
+	default:
+		fmt.Fprintln(os.Stderr, "usage: mddeck [-w width -h height] [file.md]")
+		os.Exit(2)
+	}
+	if err := convert(r, os.Stdout, *width, *height); err != nil {
+		fmt.Fprintf(os.Stderr, "mddeck: %v\n", err)
+		os.Exit(1)
+	}
+}
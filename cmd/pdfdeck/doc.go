@@ -12,7 +12,8 @@ Options     Default                                            Description
 -mono       courier                                            Monospace font
 -symbol     zapfdingbats                                       Symbol font
 
--layers     image:rect:ellipse:curve:arc:line:poly:text:list   Drawing order
+-layers     image:rect:ellipse:curve:arc:line:poly:            Drawing order
+            polyline:path:connector:table:chart:text:list:group
 -grid       0                                                  Draw a grid at specified %
 -pages      1-1000000                                          Pages to output (first-last)
 -pagesize   Letter                                             Page size (w,h) or Letter, Legal,
@@ -25,6 +26,12 @@ Options     Default                                            Description
 -sw         false                                              Use strict text wrapping
 -author     ""                                                 Document author
 -title      ""                                                 Document title
+
+-notes      false                                              Make notes pages (slide above notes)
+-handout    0                                                  Make handouts, 2, 3, 4 or 6 slides per page
+-lines      false                                              Lined note space beside handout slides
+-notepage   Letter                                             Notes and handout page size (w,h or name)
+-landscape  false                                              Landscape notes and handout pages
 ....................................................................................................
 
 # Fonts
@@ -52,6 +59,8 @@ pdfdeck -sans Go-Regular -mono Go-Mono foo.xml # use Go fonts
 pdfdeck -fontdir /path/to/fonts foo.xml        # use an alternative font directory
 pdfdeck -pages 10-12 foo.xml                   # only render pages 10, 11, and 12
 pdfdeck -pagesize A4 foo.xml                   # use A4 page size
+pdfdeck -notes foo.xml                         # notes pages, each slide above its notes, in foo-notes.pdf
+pdfdeck -handout 3 -lines foo.xml              # handouts, three slides per page with lines for notes, in foo-handout.pdf
 ```
 
 You can also read from a pipeline (for example output from the decksh command)
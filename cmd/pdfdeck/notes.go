@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"codeberg.org/go-pdf/fpdf"
+	"github.com/ajstarks/deck"
+	"github.com/ajstarks/deck/render"
+)
+
+const (
+	margin    = 36.0 // page margin, in pt
+	gutter    = 18.0 // space between handout slides, in pt
+	notefont  = 11.0 // size of note text
+	rulespace = 20.0 // space between the ruled lines of handouts
+)
+
+// notepage returns the dimensions of notes and handout pages,
+// whose size and orientation are independent of the slides
+func notepage() fpdf.SizeType {
+	w, h := pagedimen(opts.notepage)
+	if (w > h) != opts.landscape {
+		w, h = h, w
+	}
+	return fpdf.SizeType{Wd: w, Ht: h}
+}
+
+// thumbnail draws slide n, fully built and without links, scaled to fit and
+// centered in the rectangle with its upper left corner at (x,y), and frames it
+func thumbnail(doc *fpdf.Fpdf, d deck.Deck, n int, x, y, w, h float64) {
+	cw, ch := float64(d.Canvas.Width), float64(d.Canvas.Height)
+	if cw <= 0 || ch <= 0 {
+		return
+	}
+	s := math.Min(w/cw, h/ch)
+	x += (w - cw*s) / 2
+	y += (h - ch*s) / 2
+	doc.TransformBegin()
+	doc.TransformTranslate(x, y)
+	doc.TransformScale(s*100, s*100, 0, 0)
+	doc.ClipRect(0, 0, cw, ch, false)
+	render.Slide(pdfdoc{doc: doc, thumbnail: true}, d, n, render.Options{Layers: opts.layers, StrictWrap: opts.strictwrap, ShrinkImages: true})
+	doc.ClipEnd()
+	doc.TransformEnd()
+	doc.SetAlpha(1, "Normal")
+	doc.SetDashPattern(nil, 0)
+	doc.SetLineWidth(0.5)
+	doc.SetDrawColor(127, 127, 127)
+	doc.Rect(x, y, cw*s, ch*s, "D")
+}
+
+// paragraphs splits note text into paragraphs at blank lines,
+// joining the lines of each paragraph
+func paragraphs(s string) []string {
+	var p []string
+	var words []string
+	for _, line := range strings.Split(s, "\n") {
+		f := strings.Fields(line)
+		if len(f) == 0 && len(words) > 0 {
+			p = append(p, strings.Join(words, " "))
+			words = nil
+		}
+		words = append(words, f...)
+	}
+	if len(words) > 0 {
+		p = append(p, strings.Join(words, " "))
+	}
+	return p
+}
+
+// notetext writes note text wrapped to width w, from y down to the bottom margin,
+// continuing on further pages as needed
+func notetext(doc *fpdf.Fpdf, note string, x, y, w float64) {
+	tf, ok := transmap["sans"]
+	if !ok {
+		return
+	}
+	pw, ph := doc.GetPageSize()
+	leading := notefont * 1.4
+	doc.SetFont(fontlookup("sans"), "", notefont)
+	doc.SetTextColor(0, 0, 0)
+	doc.SetAlpha(1, "Normal")
+	for i, para := range paragraphs(note) {
+		if i > 0 {
+			y += leading / 2
+		}
+		for _, line := range doc.SplitText(para, w) {
+			if y+leading > ph-margin {
+				doc.AddPageFormat("P", fpdf.SizeType{Wd: pw, Ht: ph})
+				y = margin
+			}
+			y += leading
+			doc.Text(x, y, tf(line))
+		}
+	}
+}
+
+// footer centers small gray text in the bottom margin of the page
+func footer(doc *fpdf.Fpdf, s string) {
+	tf, ok := transmap["sans"]
+	if !ok {
+		return
+	}
+	pw, ph := doc.GetPageSize()
+	s = tf(s)
+	doc.SetFont(fontlookup("sans"), "", 9)
+	doc.SetTextColor(127, 127, 127)
+	doc.SetAlpha(1, "Normal")
+	doc.Text((pw-doc.GetStringWidth(s))/2, ph-margin/2, s)
+}
+
+// ruled draws lines for writing notes across the rectangle with its upper left corner at (x,y)
+func ruled(doc *fpdf.Fpdf, x, y, w, h float64) {
+	doc.SetAlpha(1, "Normal")
+	doc.SetDashPattern(nil, 0)
+	doc.SetLineWidth(0.5)
+	doc.SetDrawColor(191, 191, 191)
+	for ly := y + rulespace; ly <= y+h; ly += rulespace {
+		doc.Line(x, ly, x+w, ly)
+	}
+}
+
+// notepages makes a notes page for each slide in the page range: the slide above its notes
+func notepages(doc *fpdf.Fpdf, d deck.Deck, begin, end int) {
+	page := notepage()
+	w, h := page.Wd-2*margin, (page.Ht-2*margin)/2
+	for i := range d.Slide {
+		if i+1 < begin || i+1 > end {
+			continue
+		}
+		doc.AddPageFormat("P", page)
+		thumbnail(doc, d, i, margin, margin, w, h)
+		footer(doc, fmt.Sprintf("%d", i+1))
+		notetext(doc, d.Slide[i].Note, margin, margin+h+gutter, w)
+	}
+}
+
+// handouts places the slides in the page range n to a page: in two columns
+// for 4 or 6, otherwise in one, with rows and columns exchanged on landscape pages.
+// With lines, the slides are in one column, beside ruled note space.
+func handouts(doc *fpdf.Fpdf, d deck.Deck, n, begin, end int, lines bool) {
+	page := notepage()
+	cols := 1
+	if n >= 4 && !lines {
+		cols = 2
+	}
+	rows := n / cols
+	if page.Wd > page.Ht && !lines {
+		cols, rows = rows, cols
+	}
+	cw, ch := (page.Wd-2*margin)/float64(cols), (page.Ht-2*margin)/float64(rows)
+	k := 0
+	for i := range d.Slide {
+		if i+1 < begin || i+1 > end {
+			continue
+		}
+		if k%n == 0 {
+			doc.AddPageFormat("P", page)
+			footer(doc, fmt.Sprintf("%d", k/n+1))
+		}
+		r, c := (k%n)/cols, (k%n)%cols
+		x, y := margin+float64(c)*cw, margin+float64(r)*ch
+		if lines {
+			thumbnail(doc, d, i, x, y+gutter/2, cw/2-gutter/2, ch-gutter)
+			ruled(doc, x+cw/2+gutter/2, y+gutter/2, cw/2-gutter/2, ch-gutter)
+		} else {
+			thumbnail(doc, d, i, x+gutter/2, y+gutter/2, cw-gutter, ch-gutter)
+		}
+		k++
+	}
+}
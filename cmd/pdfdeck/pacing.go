@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"codeberg.org/go-pdf/fpdf"
+	"github.com/ajstarks/deck"
+)
+
+// transitions maps slide transitions to PDF transition dictionaries
+var transitions = map[string]string{
+	"dissolve": "/S /Dissolve",
+	"wipe":     "/S /Wipe /Di 0",
+	"fade":     "/S /Fade",
+	"push":     "/S /Push /Di 0",
+	"cover":    "/S /Cover /Di 0",
+	"uncover":  "/S /Uncover /Di 0",
+	"split":    "/S /Split /Dm /V /M /O",
+	"blinds":   "/S /Blinds /Dm /H",
+	"box":      "/S /Box /M /O",
+	"glitter":  "/S /Glitter /Di 0",
+}
+
+// pacing holds the page dictionary entries, by page number, with which viewers advance
+// the pages after the slide duration (/Dur), and enter them with its transition (/Trans)
+type pacing map[int]string
+
+// pace records the pacing of the current page, showing the build step of slide s;
+// the steps share the slide duration equally, and the transition enters the first,
+// and not the later steps
+func (p pacing) pace(doc *fpdf.Fpdf, s deck.Slide, step int) {
+	var e string
+	if t, err := time.ParseDuration(s.Duration); err == nil && t > 0 {
+		e += fmt.Sprintf("/Dur %g\n", t.Seconds()/float64(deck.Steps(s)))
+	}
+	if tr, ok := transitions[s.Transition]; ok && step == 1 {
+		e += "/Trans <<" + tr + ">>\n"
+	}
+	if e != "" {
+		p[doc.PageNo()] = e
+	}
+}
+
+// output writes the PDF document, with the pacing of its pages; fpdf has no way to add page
+// dictionary entries, so they are inserted into its output, and the cross-reference table adjusted
+func output(doc *fpdf.Fpdf, w io.Writer, pages pacing) error {
+	if len(pages) == 0 {
+		return doc.Output(w)
+	}
+	var buf bytes.Buffer
+	if err := doc.Output(&buf); err != nil {
+		return err
+	}
+	_, err := w.Write(paced(buf.Bytes(), pages))
+	return err
+}
+
+// xrefentry matches the in-use entries of the cross-reference table
+var xrefentry = regexp.MustCompile(`(?m)^(\d{10}) 00000 n `)
+
+// paced inserts entries into the page dictionaries of a PDF document made by fpdf,
+// whose pages are the first objects, in order, and whose cross-reference table follows them all
+func paced(pdf []byte, entries pacing) []byte {
+	const page, xref, startxref = "<</Type /Page\n", "\nxref\n", "startxref\n"
+	x := bytes.LastIndex(pdf, []byte(xref))
+	sx := bytes.LastIndex(pdf, []byte(startxref))
+	if x < 0 || sx < x {
+		return pdf
+	}
+	x++
+
+	// insert the entries, noting where, and how much, the objects that follow move
+	var out bytes.Buffer
+	var at, shift []int
+	pos, last := 0, 0
+	for n := range entries {
+		last = max(last, n)
+	}
+	for n := 1; n <= last; n++ {
+		i := bytes.Index(pdf[pos:x], []byte(page))
+		if i < 0 {
+			break
+		}
+		i += pos + len(page)
+		out.Write(pdf[pos:i])
+		pos = i
+		if e := entries[n]; e != "" {
+			out.WriteString(e)
+			at, shift = append(at, i), append(shift, len(e))
+		}
+	}
+	out.Write(pdf[pos:x])
+	moved := func(offset int) int {
+		k := sort.SearchInts(at, offset+1)
+		total := 0
+		for _, s := range shift[:k] {
+			total += s
+		}
+		return offset + total
+	}
+
+	// adjust the cross-reference table, and its location
+	table := xrefentry.ReplaceAllFunc(pdf[x:sx], func(e []byte) []byte {
+		offset, _ := strconv.Atoi(string(e[:10]))
+		return fmt.Appendf(nil, "%010d 00000 n ", moved(offset))
+	})
+	out.Write(table)
+	out.WriteString(startxref)
+	tail := pdf[sx+len(startxref):]
+	end := bytes.IndexByte(tail, '\n')
+	if end < 0 {
+		return pdf
+	}
+	fmt.Fprintf(&out, "%d", moved(x))
+	out.Write(tail[end:])
+	return out.Bytes()
+}
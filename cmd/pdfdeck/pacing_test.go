@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"codeberg.org/go-pdf/fpdf"
+)
+
+// testpdf returns a PDF document of n pages made by fpdf
+func testpdf(t *testing.T, n int) []byte {
+	doc := fpdf.New("L", "pt", "Letter", "")
+	doc.SetFont("helvetica", "", 12)
+	for i := 1; i <= n; i++ {
+		doc.AddPage()
+		doc.Text(72, 72, fmt.Sprintf("page %d", i))
+	}
+	var buf bytes.Buffer
+	if err := doc.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+var (
+	startxref = regexp.MustCompile(`startxref\n(\d+)\n`)
+	xrefhead  = regexp.MustCompile(`^xref\n0 (\d+)\n`)
+)
+
+// checkxref checks that the cross-reference table, found by startxref,
+// gives the offset of each object in the document
+func checkxref(t *testing.T, name string, pdf []byte) {
+	m := startxref.FindSubmatch(pdf)
+	if m == nil {
+		t.Fatalf("%s: no startxref", name)
+	}
+	x, _ := strconv.Atoi(string(m[1]))
+	h := xrefhead.FindSubmatch(pdf[x:])
+	if h == nil {
+		t.Fatalf("%s: no xref table at %d", name, x)
+	}
+	n, _ := strconv.Atoi(string(h[1]))
+	table := pdf[x+len(h[0]):]
+	for i := 1; i < n; i++ {
+		e := table[i*20 : i*20+20]
+		if !bytes.HasSuffix(e, []byte(" 00000 n \n")) {
+			continue
+		}
+		offset, _ := strconv.Atoi(string(e[:10]))
+		if obj := fmt.Sprintf("%d 0 obj", i); !bytes.HasPrefix(pdf[offset:], []byte(obj)) {
+			t.Errorf("%s: object %d: offset %d has %q", name, i, offset, pdf[offset:min(offset+len(obj), len(pdf))])
+		}
+	}
+}
+
+// pagedicts returns the page dictionaries of a document made by fpdf, in order
+func pagedicts(pdf []byte) []string {
+	var dicts []string
+	for _, p := range strings.Split(string(pdf), "<</Type /Page\n")[1:] {
+		dicts = append(dicts, p[:strings.Index(p, ">>\nendobj")])
+	}
+	return dicts
+}
+
+func TestPaced(t *testing.T) {
+	tests := []struct {
+		name    string
+		pages   int
+		entries pacing
+	}{
+		{"none", 3, pacing{}},
+		{"first", 3, pacing{1: "/Dur 2\n/Trans <</S /Fade>>\n"}},
+		{"middle", 3, pacing{2: "/Dur 1.5\n"}},
+		{"first and last", 3, pacing{1: "/Dur 2\n", 3: "/Dur 4\n/Trans <</S /Dissolve>>\n"}},
+		{"all", 2, pacing{1: "/Dur 1\n", 2: "/Dur 1\n"}},
+		{"beyond the last", 1, pacing{1: "/Dur 1\n", 2: "/Dur 1\n"}},
+	}
+	for _, test := range tests {
+		pdf := testpdf(t, test.pages)
+		out := paced(pdf, test.entries)
+		checkxref(t, test.name, out)
+		dicts := pagedicts(out)
+		if len(dicts) != test.pages {
+			t.Errorf("%s: got %d pages, want %d", test.name, len(dicts), test.pages)
+			continue
+		}
+		for i, dict := range dicts {
+			e := test.entries[i+1]
+			if !strings.HasPrefix(dict, e) || strings.Contains(dict[len(e):], "/Dur") {
+				t.Errorf("%s: page %d: got %q, want entries %q", test.name, i+1, dict, e)
+			}
+		}
+		if len(test.entries) == 0 && !bytes.Equal(out, pdf) {
+			t.Errorf("%s: document changed", test.name)
+		}
+	}
+}
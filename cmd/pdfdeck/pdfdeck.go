@@ -4,7 +4,7 @@ package main
 import (
 	"flag"
 	"fmt"
-	"image"
+	"math"
 	"os"
 	"path"
 	"path/filepath"
@@ -12,13 +12,9 @@ import (
 	"strings"
 	"unicode"
 
-	_ "image/gif"
-	_ "image/jpeg"
-	_ "image/png"
-
 	"codeberg.org/go-pdf/fpdf" //"github.com/go-pdf/fpdf"
 	"github.com/ajstarks/deck"
-	"github.com/mandolyte/mdtopdf"
+	"github.com/ajstarks/deck/render"
 )
 
 // command line options
@@ -37,24 +33,19 @@ type options struct {
 	gridpct    float64
 	width      int
 	height     int
+	notepage   string
+	handout    int
 	stdout     bool
 	strictwrap bool
+	notes      bool
+	lines      bool
+	landscape  bool
 }
 
 const (
-	mm2pt       = 2.83464 // mm to pt conversion
-	linespacing = 1.4
-	listspacing = 2.0
-	fontfactor  = 1.0
-	listwrap    = 95.0
+	mm2pt = 2.83464 // mm to pt conversion
 )
 
-type TypedString struct {
-	source   string
-	datatype string
-	data     string
-}
-
 // PageDimen describes page dimensions
 // the unit field is used to convert to pt.
 type PageDimen struct {
@@ -70,6 +61,12 @@ var fontmap = map[string]string{}
 // transmap maps generic font names to the translation function
 var transmap = map[string]func(string) string{}
 
+// styled records the bold and italic variants available for generic font names
+var styled = map[string]bool{}
+
+// fontstyles maps fpdf font styles to the suffixes of their font files
+var fontstyles = map[string]string{"B": "Bold", "I": "Italic", "BI": "BoldItalic"}
+
 // pagemap defines page dimensions
 var pagemap = map[string]PageDimen{
 	"Letter":     {792, 612, 1},
@@ -85,9 +82,6 @@ var pagemap = map[string]PageDimen{
 	"A5":         {210, 148, mm2pt},
 }
 
-// convert tabs to spaces
-var codemap = strings.NewReplacer("\t", "    ")
-
 // pagerange returns the begin and end using a "-" string
 func pagerange(s string) (int, int) {
 	p := strings.Split(s, "-")
@@ -105,14 +99,11 @@ func pagerange(s string) (int, int) {
 	return b, e
 }
 
-// pct converts percentages to canvas measures
-func pct(p, m float64) float64 {
-	return (p / 100.0) * m
-}
-
-// dimen returns canvas dimensions from percentages
-func dimen(w, h, xp, yp, sp float64) (float64, float64, float64) {
-	return pct(xp, w), pct(100-yp, h), pct(sp, w) * fontfactor
+// pdfdoc draws slide elements on a PDF document;
+// thumbnails leave out links, since their annotations are not scaled with the slide
+type pdfdoc struct {
+	doc       *fpdf.Fpdf
+	thumbnail bool
 }
 
 // setopacity sets the alpha value:
@@ -120,14 +111,7 @@ func dimen(w, h, xp, yp, sp float64) (float64, float64, float64) {
 // -1 == fully transparent
 // > 0 set opacity percent
 func setopacity(doc *fpdf.Fpdf, v float64) {
-	switch {
-	case v < 0:
-		doc.SetAlpha(0, "Normal")
-	case v > 0:
-		doc.SetAlpha(v/100, "Normal")
-	case v == 0:
-		doc.SetAlpha(1, "Normal")
-	}
+	doc.SetAlpha(render.Alpha(v), "Normal")
 }
 
 // linesettings set the line style
@@ -135,11 +119,6 @@ func linesettings(doc *fpdf.Fpdf) {
 	doc.SetLineCapStyle("butt")
 }
 
-// whitespace determines if a rune is whitespace
-func whitespace(r rune) bool {
-	return r == ' ' || r == '\n' || r == '\t'
-}
-
 // fontlookup maps font aliases to implementation font names
 func fontlookup(s string) string {
 	if font, ok := fontmap[s]; ok {
@@ -148,561 +127,268 @@ func fontlookup(s string) string {
 	return "sans"
 }
 
-// grid makes a percentage scale
-func grid(doc *fpdf.Fpdf, w, h float64, color string) {
-	percent := opts.gridpct
-	pw := w * (percent / 100)
-	ph := h * (percent / 100)
-	doc.SetLineWidth(0.5)
-	r, g, b := colorlookup(color)
-	doc.SetDrawColor(r, g, b)
-	doc.SetTextColor(r, g, b)
-	fs := pct(1, w)
-	for x, pl := 0.0, 0.0; x <= w; x += pw {
-		doc.Line(x, 0, x, h)
-		if pl > 0 {
-			showtext(doc, x, h-fs, fmt.Sprintf("%.0f", pl), fs, "sans", "center", "")
-		}
-		pl += percent
-	}
-	for y, pl := 0.0, 0.0; y <= h; y += ph {
-		doc.Line(0, y, w, y)
-		if pl < 100 {
-			showtext(doc, fs, y+(fs/3), fmt.Sprintf("%.0f", 100-pl), fs, "sans", "center", "")
-		}
-		pl += percent
-	}
+// fill sets the fill color and opacity
+func (p pdfdoc) fill(s render.Style) {
+	c, alpha := render.Color(s.Color, s.Opacity)
+	p.doc.SetFillColor(int(c.R), int(c.G), int(c.B))
+	p.doc.SetAlpha(alpha, "Normal")
 }
 
-// bullet draws a bullet
-func bullet(doc *fpdf.Fpdf, x, y, size float64, color string) {
-	rs := size / 2
-	r, g, b := colorlookup(color)
-	doc.SetFillColor(r, g, b)
-	doc.Circle(x-size*2, y-rs, rs, "F")
+// stroke sets the stroke color, width, opacity, dash pattern, line cap and line join
+func (p pdfdoc) stroke(s render.Style) {
+	c, alpha := render.Color(s.Color, s.Opacity)
+	p.doc.SetLineWidth(s.Width)
+	p.doc.SetDrawColor(int(c.R), int(c.G), int(c.B))
+	p.doc.SetAlpha(alpha, "Normal")
+	p.doc.SetDashPattern(s.Dash, 0)
+	if s.Cap == "" {
+		p.doc.SetLineCapStyle("butt")
+	} else {
+		p.doc.SetLineCapStyle(s.Cap)
+	}
+	p.doc.SetLineJoinStyle(s.Join)
+}
+
+// paint sets the fill and outline of a shape, returning the drawing style:
+// F to fill, D to outline, FD for both. Gradient fills are drawn separately (see shade).
+func (p pdfdoc) paint(s render.Style) string {
+	var style string
+	if !s.NoFill && s.Gradient == nil {
+		p.fill(s)
+		style = "F"
+	}
+	if s.Stroke != "" {
+		o := s
+		o.Color = s.Stroke
+		p.stroke(o)
+		style += "D"
+	}
+	return style
+}
+
+// gradient fills the clipping area with a gradient, drawn across a square
+// centered on the gradient and large enough to cover the page.
+// PDF gradients are opaque: the opacity of the first color applies to both.
+func (p pdfdoc) gradient(g *render.Gradient, opacity float64) {
+	c1, alpha := render.Color(g.Color1, opacity)
+	c2, _ := render.Color(g.Color2, opacity)
+	pw, ph := p.doc.GetPageSize()
+	l := pw + ph + math.Abs(g.X1) + math.Abs(g.Y1) + math.Abs(g.X2) + math.Abs(g.Y2) + g.R
+	x, y := g.X1-l, g.Y1-l
+	// normalized coordinates within the square, with the origin at its lower left
+	nx := func(v float64) float64 { return (v - x) / (2 * l) }
+	ny := func(v float64) float64 { return (y + 2*l - v) / (2 * l) }
+	p.doc.SetAlpha(alpha, "Normal")
+	if g.Radial {
+		p.doc.RadialGradient(x, y, 2*l, 2*l, int(c1.R), int(c1.G), int(c1.B), int(c2.R), int(c2.G), int(c2.B),
+			nx(g.X1), ny(g.Y1), nx(g.X1), ny(g.Y1), g.R/(2*l))
+		return
+	}
+	p.doc.LinearGradient(x, y, 2*l, 2*l, int(c1.R), int(c1.G), int(c1.B), int(c2.R), int(c2.G), int(c2.B),
+		nx(g.X1), ny(g.Y1), nx(g.X2), ny(g.Y2))
 }
 
-// background places a colored rectangle
-func background(doc *fpdf.Fpdf, w, h float64, color string) {
-	rectangle(doc, 0, 0, w, h, color)
+// shade fills a shape with the gradient of its style, if any, within the clipping area set by clip
+func (p pdfdoc) shade(s render.Style, clip func()) {
+	if s.NoFill || s.Gradient == nil {
+		return
+	}
+	clip()
+	p.gradient(s.Gradient, s.Opacity)
+	p.doc.ClipEnd()
 }
 
-// gradientbg  sets the background color gradient
-func gradientbg(doc *fpdf.Fpdf, w, h float64, gc1, gc2 string, gp float64) {
-	gradient(doc, 0, 0, w, h, gc1, gc2, gp)
+// Rect draws a rectangle
+func (p pdfdoc) Rect(x, y, w, h float64, s render.Style) {
+	p.shade(s, func() { p.doc.ClipRect(x, y, w, h, false) })
+	if style := p.paint(s); style != "" {
+		p.doc.Rect(x, y, w, h, style)
+	}
 }
 
-// gradient  sets the background color gradient
-func gradient(doc *fpdf.Fpdf, x, y, w, h float64, gc1, gc2 string, gp float64) {
-	r1, g1, b1 := colorlookup(gc1)
-	r2, g2, b2 := colorlookup(gc2)
-	gp /= 100.0
-	doc.LinearGradient(x, y, w, h, r1, g1, b1, r2, g2, b2, 0, gp, 1, 1)
+// Ellipse draws an ellipse
+func (p pdfdoc) Ellipse(x, y, w, h float64, s render.Style) {
+	p.shade(s, func() { p.doc.ClipEllipse(x, y, w, h, false) })
+	if style := p.paint(s); style != "" {
+		p.doc.Ellipse(x, y, w, h, 0, style)
+	}
 }
 
-// line draws a line
-func line(doc *fpdf.Fpdf, xp1, yp1, xp2, yp2, sw float64, color string) {
-	r, g, b := colorlookup(color)
-	doc.SetLineWidth(sw)
-	doc.SetDrawColor(r, g, b)
-	doc.Line(xp1, yp1, xp2, yp2)
+// Arc draws an arc
+func (p pdfdoc) Arc(x, y, w, h, a1, a2 float64, s render.Style) {
+	p.stroke(s)
+	p.doc.Arc(x, y, w, h, 0, a1, a2, "D")
 }
 
-// arc draws a line
-func arc(doc *fpdf.Fpdf, x, y, w, h, a1, a2, sw float64, color string) {
-	r, g, b := colorlookup(color)
-	doc.SetLineWidth(sw)
-	doc.SetDrawColor(r, g, b)
-	doc.Arc(x, y, w, h, 0, a1, a2, "D")
+// Curve draws a quadradic bezier curve
+func (p pdfdoc) Curve(x1, y1, x2, y2, x3, y3 float64, s render.Style) {
+	p.stroke(s)
+	p.doc.Curve(x1, y1, x2, y2, x3, y3, "D")
 }
 
-// quadcurve draws a quadradic bezier curve
-func quadcurve(doc *fpdf.Fpdf, xp1, yp1, xp2, yp2, xp3, yp3, sw float64, color string) {
-	r, g, b := colorlookup(color)
-	doc.SetLineWidth(sw)
-	doc.SetDrawColor(r, g, b)
-	doc.Curve(xp1, yp1, xp2, yp2, xp3, yp3, "D")
+// Line draws a line
+func (p pdfdoc) Line(x1, y1, x2, y2 float64, s render.Style) {
+	p.stroke(s)
+	p.doc.Line(x1, y1, x2, y2)
 }
 
-// rectangle draws a rectangle
-func rectangle(doc *fpdf.Fpdf, x, y, w, h float64, color string) {
-	r, g, b := colorlookup(color)
-	doc.SetFillColor(r, g, b)
-	doc.Rect(x, y, w, h, "F")
+// Polygon draws a polygon
+func (p pdfdoc) Polygon(x, y []float64, s render.Style) {
+	poly := make([]fpdf.PointType, len(x))
+	for i := range x {
+		poly[i].X = x[i]
+		poly[i].Y = y[i]
+	}
+	p.shade(s, func() { p.doc.ClipPolygon(poly, false) })
+	if style := p.paint(s); style != "" {
+		p.doc.Polygon(poly, style)
+	}
 }
 
-// ellipse draws a rectangle
-func ellipse(doc *fpdf.Fpdf, x, y, w, h float64, color string) {
-	r, g, b := colorlookup(color)
-	doc.SetFillColor(r, g, b)
-	doc.Ellipse(x, y, w, h, 0, "F")
+// Polyline draws connected line segments
+func (p pdfdoc) Polyline(x, y []float64, s render.Style) {
+	p.stroke(s)
+	p.doc.MoveTo(x[0], y[0])
+	for i := 1; i < len(x); i++ {
+		p.doc.LineTo(x[i], y[i])
+	}
+	p.doc.DrawPath("D")
 }
 
-// polygon draws a polygon
-func polygon(doc *fpdf.Fpdf, xc, yc, color string, cw, ch float64) {
-	xs := strings.Split(xc, " ")
-	ys := strings.Split(yc, " ")
-	if len(xs) != len(ys) {
-		return
-	}
-	if len(xs) < 3 || len(ys) < 3 {
-		return
-	}
-	poly := make([]fpdf.PointType, len(xs))
-	for i := range xs {
-		x, err := strconv.ParseFloat(xs[i], 64)
-		if err != nil {
-			poly[i].X = 0
-		} else {
-			poly[i].X = pct(x, cw)
-		}
-		y, err := strconv.ParseFloat(ys[i], 64)
-		if err != nil {
-			poly[i].Y = 0
-		} else {
-			poly[i].Y = pct(100-y, ch)
+// pathops adds the operations of a path to the current path
+func (p pdfdoc) pathops(ops []render.PathOp) {
+	for _, op := range ops {
+		switch op.Op {
+		case 'M':
+			p.doc.MoveTo(op.X[0], op.Y[0])
+		case 'L':
+			p.doc.LineTo(op.X[0], op.Y[0])
+		case 'Q':
+			p.doc.CurveTo(op.X[0], op.Y[0], op.X[1], op.Y[1])
+		case 'C':
+			p.doc.CurveBezierCubicTo(op.X[0], op.Y[0], op.X[1], op.Y[1], op.X[2], op.Y[2])
+		case 'Z':
+			p.doc.ClosePath()
 		}
 	}
-	r, g, b := colorlookup(color)
-	doc.SetFillColor(r, g, b)
-	doc.Polygon(poly, "F")
-}
-
-// content places text elements on the canvas according to type
-func textcontent(doc *fpdf.Fpdf, cw, x, y, fs float64, tdata TypedString, t deck.Text) {
-	wp := t.Wp
-	rotation := t.Rotation
-	spacing := t.Lp
-	font := t.Font
-	align := t.Align
-	tlink := t.Link
-
-	if rotation > 0 {
-		doc.TransformBegin()
-		doc.TransformRotate(rotation, x, y)
-	}
-	red, green, blue := colorlookup(t.Color)
-	doc.SetTextColor(red, green, blue)
-
-	var tw float64
-	switch t.Type {
-	case "code":
-		font = "mono"
-		codemap.Replace(tdata.data)
-		td := strings.Split(tdata.data, "\n")
-		ch := float64(len(td)) * spacing * fs
-		tw = deck.Pwidth(wp, cw, cw-x-20)
-		rectangle(doc, x-fs, y-fs, tw, ch, "rgb(240,240,240)")
-		plaintext(doc, td, x, y, spacing, fs, font, align, tlink)
-	case "block":
-		tw = deck.Pwidth(wp, cw, cw/2)
-		textwrap(doc, x, y, tw, fs, fs*spacing, transmap[font](tdata.data), font, tlink)
-	case "markdown":
-		markdown(tdata)
-	default:
-		codemap.Replace(tdata.data)
-		td := strings.Split(tdata.data, "\n")
-		plaintext(doc, td, x, y, spacing, fs, font, align, tlink)
-	}
-	if rotation > 0 {
-		doc.TransformEnd()
-	}
 }
 
-// plaintext places lines of text
-func plaintext(doc *fpdf.Fpdf, td []string, x, y, spacing, fs float64, font, align, tlink string) {
-	ls := spacing * fs
-	for _, t := range td {
-		showtext(doc, x, y, t, fs, font, align, tlink)
-		y += ls
+// Path draws a path; gradients are clipped to the path directly, since fpdf has no path clipping
+func (p pdfdoc) Path(ops []render.PathOp, s render.Style) {
+	if !s.NoFill && s.Gradient != nil {
+		p.doc.RawWriteStr("q")
+		p.pathops(ops)
+		p.doc.RawWriteStr("W n")
+		p.gradient(s.Gradient, s.Opacity)
+		p.doc.RawWriteStr("Q")
 	}
+	style := p.paint(s)
+	if style == "" {
+		return
+	}
+	p.pathops(ops)
+	p.doc.DrawPath(style)
 }
 
-// markdown creates a separate PDF from markdown
-func markdown(tdata TypedString) {
-	pf := mdtopdf.NewPdfRenderer("", "", tdata.source+".pdf", "")
-	pf.Process([]byte(tdata.data))
-}
-
-// showtext places fully attributed text at the specified location
-func showtext(doc *fpdf.Fpdf, x, y float64, s string, fs float64, font, align, link string) {
-	offset := 0.0
-	doc.SetFont(fontlookup(font), "", fs)
-	var t string
-	tf, ok := transmap[font]
-	if ok {
-		t = tf(s)
-	} else {
+// Text places fully attributed text at the specified location
+func (p pdfdoc) Text(x, y float64, s string, st render.Style) {
+	tf, ok := transmap[st.Font]
+	if !ok {
 		return
 	}
-	//t := transmap[font](s)
-	tw := doc.GetStringWidth(t)
-	switch align {
+	offset := 0.0
+	t := tf(s)
+	c, alpha := render.Color(st.Color, st.Opacity)
+	p.doc.SetTextColor(int(c.R), int(c.G), int(c.B))
+	p.doc.SetAlpha(alpha, "Normal")
+	p.doc.SetFont(fontlookup(st.Font), fontstyle(st), st.Size)
+	tw := p.doc.GetStringWidth(t)
+	switch st.Align {
 	case "center", "middle", "mid", "c":
 		offset = (tw / 2)
 	case "right", "end", "e":
 		offset = tw
 	}
-	doc.Text(x-offset, y, t)
-	if len(link) > 0 {
-		doc.LinkString(x-offset, y-fs, tw, fs, link)
+	p.doc.Text(x-offset, y, t)
+	if len(st.Link) > 0 && !p.thumbnail {
+		p.doc.LinkString(x-offset, y-st.Size, tw, st.Size, st.Link)
 	}
 }
 
-// list places lists on the canvas
-func list(doc *fpdf.Fpdf, cw, x, y, fs float64, l deck.List) {
-	rotation := l.Rotation
-	font := l.Font
-	color := l.Color
-	align := l.Align
-	ltype := l.Type
-
-	if font == "" {
-		font = "sans"
-	}
-	red, green, blue := colorlookup(color)
-
-	if ltype == "bullet" {
-		x += fs * 1.2
-	}
-	ls := l.Lp * fs
-	tw := deck.Pwidth(l.Wp, cw, cw/2)
-
-	var t string
-	var yw int
-
-	if rotation > 0 {
-		doc.TransformBegin()
-		doc.TransformRotate(rotation, x, y)
-	}
-	defont := font
-	for i, tl := range l.Li {
-		doc.SetFont(fontlookup(font), "", fs)
-		doc.SetTextColor(red, green, blue)
-		setopacity(doc, tl.Opacity)
-		if ltype == "number" {
-			t = fmt.Sprintf("%d. ", i+1) + tl.ListText
-		} else {
-			t = tl.ListText
-		}
-		if ltype == "bullet" {
-			bullet(doc, x, y, fs/2, color)
-		}
-		if len(tl.Color) > 0 {
-			tlred, tlgreen, tlblue := colorlookup(tl.Color)
-			doc.SetTextColor(tlred, tlgreen, tlblue)
-		}
-		if len(tl.Font) > 0 {
-			doc.SetFont(fontlookup(tl.Font), "", fs)
-			font = tl.Font
-		} else {
-			font = defont
-		}
-		if align == "center" || align == "c" {
-			showtext(doc, x, y, transmap[font](t), fs, font, align, "")
-			y += ls
-		} else {
-
-			yw = textwrap(doc, x, y, tw, fs, ls, transmap[font](t), font, "")
-
-			y += ls
-			if yw >= 1 {
-				y += ls * float64(yw)
-			}
-		}
-	}
-	if rotation > 0 {
-		doc.TransformEnd()
+// TextWidth returns the width of text
+func (p pdfdoc) TextWidth(s string, st render.Style) float64 {
+	if tf, ok := transmap[st.Font]; ok {
+		s = tf(s)
 	}
+	p.doc.SetFont(fontlookup(st.Font), fontstyle(st), st.Size)
+	return p.doc.GetStringWidth(s)
 }
 
-// textwrap draws text at location, wrapping at the specified width
-func textwrap(doc *fpdf.Fpdf, x, y, w, fs, leading float64, s, font, link string) int {
-	var factor = 0.3
-	if font == "mono" {
-		factor = 1.0
-	}
-	nbreak := 0
-	doc.SetFont(fontlookup(font), "", fs)
-	wordspacing := doc.GetStringWidth("M")
-	words := strings.FieldsFunc(s, whitespace)
-	xp := x
-	yp := y
-	edge := x + w
-
-	for _, s := range words {
-		if s == "\\n" { // magic new line
-			xp = x
-			yp += (leading * 1.5)
-			nbreak++
-			continue
-		}
-		if opts.strictwrap {
-			tw := doc.GetStringWidth(s)
-			if xp+tw > edge {
-				xp = x
-				yp += leading
-			}
-			doc.Text(xp, yp, s)
-			xp += tw + (wordspacing * factor)
-
-		} else {
-			tw := doc.GetStringWidth(s)
-			doc.Text(xp, yp, s)
-			xp += tw + (wordspacing * factor)
-			if xp > edge {
-				xp = x
-				yp += leading
-				nbreak++
-			}
-		}
-	}
-	if len(link) > 0 {
-		doc.LinkString(x, y-fs, edge, (yp-y)+fs, link)
+// Image places an image centered at (x,y)
+func (p pdfdoc) Image(x, y, w, h float64, name string, s render.Style) {
+	var imgopt fpdf.ImageOptions
+	imgopt.AllowNegativePosition = true
+	link := s.Link
+	if p.thumbnail {
+		link = ""
 	}
-	return nbreak
+	setopacity(p.doc, s.Opacity)
+	p.doc.ImageOptions(name, x-(w/2), y-(h/2), w, h, false, imgopt, 0, link)
 }
 
-// content reads data from a file, returning a tab-expanded string
-func filecontent(scheme, path string) string {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "%v\n", err)
-		return ""
-	}
-	return codemap.Replace(string(data))
+// Rotate begins a rotation about (x,y)
+func (p pdfdoc) Rotate(x, y, angle float64) {
+	p.doc.TransformBegin()
+	p.doc.TransformRotate(angle, x, y)
 }
 
-// includefile returns the contents of a file as string
-func includefile(filetype, filename string) TypedString {
-	var ts TypedString
-	ts.source = filename
-	ts.datatype = filetype
-	ts.data = filecontent(filetype, filename)
-	return ts
+// EndRotate ends a rotation
+func (p pdfdoc) EndRotate() {
+	p.doc.TransformEnd()
 }
 
-// pdfslide makes a slide, one slide per PDF page
-func pdfslide(doc *fpdf.Fpdf, d deck.Deck, n int, showslide bool) {
+// pdfslide makes a slide, one PDF page per build step, paced by the slide duration and transition
+func pdfslide(doc *fpdf.Fpdf, d deck.Deck, n int, showslide bool, pages pacing) {
 	if n < 0 || n > len(d.Slide)-1 || !showslide {
 		return
 	}
+	for step := 1; step <= deck.Steps(d.Slide[n]); step++ {
+		doc.AddPage()
+		pages.pace(doc, d.Slide[n], step)
+		render.Slide(pdfdoc{doc: doc}, d, n, render.Options{Layers: opts.layers, Grid: opts.gridpct, StrictWrap: opts.strictwrap, Step: step, ShrinkImages: true})
+	}
+}
 
-	var x, y, fs float64
-	var imgopt fpdf.ImageOptions
-	imgopt.AllowNegativePosition = true
+// fontvariant names the file of a font's style variant:
+// helvetica -> helveticab for core (json) fonts,
+// FiraSans-Regular -> FiraSans-Bold for TrueType fonts
+func fontvariant(font, style string, core bool) string {
+	if core {
+		return font + strings.ToLower(style)
+	}
+	return strings.TrimSuffix(font, "-Regular") + "-" + fontstyles[style]
+}
 
-	doc.AddPage()
-	cw := float64(d.Canvas.Width)
-	ch := float64(d.Canvas.Height)
-	slide := d.Slide[n]
-	// set default background
-	if slide.Bg == "" {
-		slide.Bg = "white"
-	}
-	background(doc, cw, ch, slide.Bg)
-
-	if slide.GradPercent <= 0 || slide.GradPercent > 100 {
-		slide.GradPercent = 100
-	}
-	// set gradient background, if specified. You need both colors
-	if len(slide.Gradcolor1) > 0 && len(slide.Gradcolor2) > 0 {
-		gradientbg(doc, cw, ch, slide.Gradcolor1, slide.Gradcolor2, slide.GradPercent)
-	}
-	// set the default foreground
-	if slide.Fg == "" {
-		slide.Fg = "black"
-	}
-
-	const defaultColor = "rgb(127,127,127)"
-	layerlist := strings.Split(opts.layers, ":")
-	// draw elements in the order of the layer list
-	for il := range layerlist {
-		switch layerlist[il] {
-		case "image":
-			// for every image on the slide...
-			for _, im := range slide.Image {
-				x, y, _ = dimen(cw, ch, im.Xp, im.Yp, 0)
-				fw, fh := float64(im.Width), float64(im.Height)
-				// scale the image by the specified percentage
-				if im.Scale > 0 {
-					fw *= (im.Scale / 100)
-					fh *= (im.Scale / 100)
-				}
-				// scale the image to fit the canvas width
-				if im.Autoscale == "on" && fw > cw {
-					fh *= (cw / fw)
-					fw = cw
-				}
-				// scale the image to a percentage of the canvas width
-				if im.Height == 0 && im.Width > 0 {
-					nw, nh := imageInfo(im.Name)
-					if nh > 0 {
-						imscale := (fw / 100) * cw
-						fw = imscale
-						fh = imscale / (float64(nw) / float64(nh))
-					}
-				}
-				midx := fw / 2
-				midy := fh / 2
-				doc.ImageOptions(im.Name, x-midx, y-midy, fw, fh, false, imgopt, 0, im.Link)
-				if len(im.Caption) > 0 {
-					capsize := deck.Pwidth(im.Sp, cw, pct(2, cw))
-					if im.Font == "" {
-						im.Font = "sans"
-					}
-					if im.Color == "" {
-						im.Color = slide.Fg
-					}
-					if im.Align == "" {
-						im.Align = "center"
-					}
-					switch im.Align {
-					case "left", "start":
-						x -= midx
-					case "right", "end":
-						x += midx
-					}
-					capr, capg, capb := colorlookup(im.Color)
-					doc.SetTextColor(capr, capg, capb)
-					showtext(doc, x, y+(midy)+(capsize*1.5), im.Caption, capsize, im.Font, im.Align, "")
-				}
-			}
-		case "rect":
-			// rect
-			for _, r := range slide.Rect {
-				x, y, _ := dimen(cw, ch, r.Xp, r.Yp, 0)
-				var w, h float64
-				w = pct(r.Wp, cw)
-				if r.Hr == 0 {
-					h = pct(r.Hp, ch)
-				} else {
-					h = pct(r.Hr, w)
-				}
-				if r.Color == "" {
-					r.Color = defaultColor
-				}
-				if len(r.Gradcolor1) > 0 && len(r.Gradcolor2) > 0 {
-					gradient(doc, x-(w/2), y-(h/2), w, h, r.Gradcolor1, r.Gradcolor2, r.GradPercent)
-				} else {
-					setopacity(doc, r.Opacity)
-					rectangle(doc, x-(w/2), y-(h/2), w, h, r.Color)
-				}
-			}
-		case "ellipse":
-			// ellipse
-			for _, e := range slide.Ellipse {
-				x, y, _ := dimen(cw, ch, e.Xp, e.Yp, 0)
-				var w, h float64
-				w = pct(e.Wp, cw)
-				if e.Hr == 0 {
-					h = pct(e.Hp, ch)
-				} else {
-					h = pct(e.Hr, w)
-				}
-				if e.Color == "" {
-					e.Color = defaultColor
-				}
-				setopacity(doc, e.Opacity)
-				ellipse(doc, x, y, w/2, h/2, e.Color)
-			}
-		case "curve":
-			// curve
-			for _, c := range slide.Curve {
-				if c.Color == "" {
-					c.Color = defaultColor
-				}
-				setopacity(doc, c.Opacity)
-				x1, y1, sw := dimen(cw, ch, c.Xp1, c.Yp1, c.Sp)
-				x2, y2, _ := dimen(cw, ch, c.Xp2, c.Yp2, 0)
-				x3, y3, _ := dimen(cw, ch, c.Xp3, c.Yp3, 0)
-				if sw == 0 {
-					sw = 2.0
-				}
-				quadcurve(doc, x1, y1, x2, y2, x3, y3, sw, c.Color)
-			}
-		case "arc":
-			// arc
-			for _, a := range slide.Arc {
-				if a.Color == "" {
-					a.Color = defaultColor
-				}
-				setopacity(doc, a.Opacity)
-				x, y, sw := dimen(cw, ch, a.Xp, a.Yp, a.Sp)
-				w := pct(a.Wp, cw)
-				h := pct(a.Hp, cw)
-				if sw == 0 {
-					sw = 2.0
-				}
-				arc(doc, x, y, w/2, h/2, a.A1, a.A2, sw, a.Color)
-			}
-		case "line":
-			// line
-			for _, l := range slide.Line {
-				if l.Color == "" {
-					l.Color = defaultColor
-				}
-				setopacity(doc, l.Opacity)
-				x1, y1, sw := dimen(cw, ch, l.Xp1, l.Yp1, l.Sp)
-				x2, y2, _ := dimen(cw, ch, l.Xp2, l.Yp2, 0)
-				if sw == 0 {
-					sw = 2.0
-				}
-				line(doc, x1, y1, x2, y2, sw, l.Color)
-			}
-		case "poly":
-			// polygon
-			for _, p := range slide.Polygon {
-				if p.Color == "" {
-					p.Color = defaultColor
-				}
-				setopacity(doc, p.Opacity)
-				polygon(doc, p.XC, p.YC, p.Color, cw, ch)
-			}
-		case "text":
-			// for every text element...
-			var tdata TypedString
-			for _, t := range slide.Text {
-				if t.Color == "" {
-					t.Color = slide.Fg
-				}
-				if t.Font == "" {
-					t.Font = "sans"
-				}
-				setopacity(doc, t.Opacity)
-				x, y, fs = dimen(cw, ch, t.Xp, t.Yp, t.Sp)
-				if t.File != "" {
-					tdata = includefile(t.Type, t.File)
-				} else {
-					tdata.data = t.Tdata
-				}
-				if t.Lp == 0 {
-					t.Lp = linespacing
-				}
-				textcontent(doc, cw, x, y, fs, tdata, t)
-			}
-		case "list":
-			// for every list element...
-			for _, l := range slide.List {
-				if l.Color == "" {
-					l.Color = slide.Fg
-				}
-				if l.Lp == 0 {
-					l.Lp = listspacing
-				}
-				if l.Wp == 0 {
-					l.Wp = listwrap
-				}
-				setopacity(doc, l.Opacity)
-				x, y, fs = dimen(cw, ch, l.Xp, l.Yp, l.Sp)
-				list(doc, cw, x, y, fs, l)
-			}
-		}
+// fontstyle returns the fpdf style for bold and italic text,
+// falling back to regular if the font has no such variant
+func fontstyle(st render.Style) string {
+	var s string
+	if st.Bold {
+		s += "B"
+	}
+	if st.Italic {
+		s += "I"
 	}
-	// add a grid, if specified
-	if opts.gridpct > 0 {
-		grid(doc, cw, ch, slide.Fg)
+	if styled[st.Font+s] {
+		return s
 	}
+	return ""
+}
+
+// exists determines if the named file exists
+func exists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
 }
 
 // nulltrans is the null translation function
@@ -710,8 +396,8 @@ func nulltrans(s string) string {
 	return s
 }
 
-// slides reads the deck file, making the PDF version
-func slides(doc *fpdf.Fpdf, pc fpdf.InitType, filename string, begin, end int) {
+// slides reads the deck file, making the PDF version, and recording the pacing of its pages
+func slides(doc *fpdf.Fpdf, pc fpdf.InitType, filename string, begin, end int, pages pacing) {
 	var d deck.Deck
 	var err error
 
@@ -727,6 +413,19 @@ func slides(doc *fpdf.Fpdf, pc fpdf.InitType, filename string, begin, end int) {
 			doc.AddFont(v, "", v+".json")
 			transmap[k] = doc.UnicodeTranslatorFromDescriptor("")
 		}
+		// bold and italic variants, if present
+		for style := range fontstyles {
+			vf := fontvariant(v, style, err == nil)
+			switch {
+			case err == nil && exists(filepath.Join(pc.FontDirStr, vf+".json")):
+				doc.AddFont(v, style, vf+".json")
+			case err != nil && exists(filepath.Join(pc.FontDirStr, vf+".ttf")):
+				doc.AddUTF8Font(v, style, vf+".ttf")
+			default:
+				continue
+			}
+			styled[k+style] = true
+		}
 	}
 	d, err = deck.Read(filename, w, h)
 	if err != nil {
@@ -759,8 +458,15 @@ func slides(doc *fpdf.Fpdf, pc fpdf.InitType, filename string, begin, end int) {
 	if len(d.Subject) > 0 {
 		doc.SetSubject(d.Subject, true)
 	}
-	for i := range d.Slide {
-		pdfslide(doc, d, i, (i+1 >= begin && i+1 <= end))
+	switch {
+	case opts.notes:
+		notepages(doc, d, begin, end)
+	case opts.handout > 0:
+		handouts(doc, d, opts.handout, begin, end, opts.lines)
+	default:
+		for i := range d.Slide {
+			pdfslide(doc, d, i, (i+1 >= begin && i+1 <= end), pages)
+		}
 	}
 }
 
@@ -772,27 +478,36 @@ func pdfdeck(files []string, pageconfig fpdf.InitType, begin, end int) {
 	if opts.stdout { // combined output to standard output
 		doc := fpdf.NewCustom(pc)
 		linesettings(doc)
+		pages := pacing{}
 		for _, filename := range files {
-			slides(doc, pageconfig, filename, begin, end)
+			slides(doc, pageconfig, filename, begin, end, pages)
 		}
-		err := doc.Output(os.Stdout)
+		err := output(doc, os.Stdout, pages)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "pdfdeck: %v\n", err)
 		}
 		return
 	}
-	// output to individual files
+	// output to individual files, named for notes pages and handouts
+	suffix := ".pdf"
+	switch {
+	case opts.notes:
+		suffix = "-notes.pdf"
+	case opts.handout > 0:
+		suffix = "-handout.pdf"
+	}
 	for _, filename := range files {
-		base := strings.Split(filepath.Base(filename), ".xml")
-		out, err := os.Create(filepath.Join(opts.outdir, base[0]+".pdf"))
+		base := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+		out, err := os.Create(filepath.Join(opts.outdir, base+suffix))
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "pdfdeck: file %q - %v\n", filename, err)
 			continue
 		}
 		doc := fpdf.NewCustom(pc)
 		linesettings(doc)
-		slides(doc, pageconfig, filename, begin, end)
-		err = doc.Output(out)
+		pages := pacing{}
+		slides(doc, pageconfig, filename, begin, end, pages)
+		err = output(doc, out, pages)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "pdfdeck: file %q - %v\n", filename, err)
 			continue
@@ -820,6 +535,20 @@ func setpagesize(s string) (float64, float64) {
 	return width, height
 }
 
+// pagedimen returns the dimensions of a page size, w,h or a named size, Letter by default
+func pagedimen(s string) (float64, float64) {
+	w, h := setpagesize(s)
+	if w == 0 && h == 0 {
+		p, ok := pagemap[s]
+		if !ok {
+			p = pagemap["Letter"]
+		}
+		w = p.width * p.unit
+		h = p.height * p.unit
+	}
+	return w, h
+}
+
 // setfontdir determines the font directory:
 // if the string argument is non-empty, use that, otherwise
 // use the contents of the DECKFONT environment variable,
@@ -835,20 +564,6 @@ func setfontdir(s string) string {
 	return path.Join(os.Getenv("HOME"), "deckfonts")
 }
 
-// imageInfo returns the dimensions of an image
-func imageInfo(s string) (int, int) {
-	f, err := os.Open(s)
-	if err != nil {
-		return 0, 0
-	}
-	defer f.Close()
-	im, _, err := image.DecodeConfig(f)
-	if err != nil {
-		return 0, 0
-	}
-	return im.Width, im.Height
-}
-
 var usage = `
 pdfdeck [options] file...
 
@@ -859,7 +574,8 @@ Options     Default                                            Description
 -mono       courier                                            Monospace font
 -symbol     zapfdingbats                                       Symbol font
 
--layers     image:rect:ellipse:curve:arc:line:poly:text:list   Drawing order
+-layers     image:rect:ellipse:curve:arc:line:poly:            Drawing order
+            polyline:path:connector:table:chart:text:list:group
 -grid       0                                                  Draw a grid at specified %
 -pages      1-1000000                                          Pages to output (first-last)
 -pagesize   Letter                                             Page size (w,h) or Letter, Legal,
@@ -871,6 +587,12 @@ Options     Default                                            Description
 -sw         false                                              Use strict text wrapping
 -author     ""                                                 Document author
 -title      ""                                                 Document title
+
+-notes      false                                              Make notes pages (slide above notes)
+-handout    0                                                  Make handouts, 2, 3, 4 or 6 slides per page
+-lines      false                                              Lined note space beside handout slides
+-notepage   Letter                                             Notes and handout page size (w,h or name)
+-landscape  false                                              Landscape notes and handout pages
 ....................................................................................................`
 
 func cmdUsage() {
@@ -889,23 +611,26 @@ func main() {
 	flag.StringVar(&opts.outdir, "outdir", ".", "output directory")
 	flag.StringVar(&opts.title, "title", "", "document title")
 	flag.StringVar(&opts.author, "author", "", "document author")
-	flag.StringVar(&opts.layers, "layers", "image:rect:ellipse:curve:arc:line:poly:text:list", "Layer order")
+	flag.StringVar(&opts.layers, "layers", "image:rect:ellipse:curve:arc:line:poly:polyline:path:connector:table:chart:text:list:group", "Layer order")
 	flag.Float64Var(&opts.gridpct, "grid", 0, "draw a percentage grid on each slide")
 	flag.StringVar(&opts.pages, "pages", "1-1000000", "page range (first-last)")
 	flag.BoolVar(&opts.stdout, "stdout", false, "output to standard output")
 	flag.BoolVar(&opts.strictwrap, "sw", false, "strict text wrap")
+	flag.BoolVar(&opts.notes, "notes", false, "make notes pages: each slide above its notes")
+	flag.IntVar(&opts.handout, "handout", 0, "make handouts with 2, 3, 4 or 6 slides per page")
+	flag.BoolVar(&opts.lines, "lines", false, "lined note space beside handout slides")
+	flag.StringVar(&opts.notepage, "notepage", "Letter", "notes and handout page size: w,h, or a named page size")
+	flag.BoolVar(&opts.landscape, "landscape", false, "landscape notes and handout pages")
 	flag.Usage = cmdUsage
 	flag.Parse()
 
 	// set page dimensions
-	pw, ph := setpagesize(opts.pagesize)
-	if pw == 0 && ph == 0 {
-		p, ok := pagemap[opts.pagesize]
-		if !ok {
-			p = pagemap["Letter"]
-		}
-		pw = p.width * p.unit
-		ph = p.height * p.unit
+	pw, ph := pagedimen(opts.pagesize)
+	switch opts.handout {
+	case 0, 2, 3, 4, 6:
+	default:
+		fmt.Fprintf(os.Stderr, "pdfdeck: %d slides per handout page; use 2, 3, 4 or 6\n", opts.handout)
+		os.Exit(1)
 	}
 	pageconfig := fpdf.InitType{
 		UnitStr:    "pt",
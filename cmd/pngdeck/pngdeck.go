@@ -14,16 +14,13 @@ import (
 	"unicode"
 
 	"github.com/ajstarks/deck"
+	"github.com/ajstarks/deck/render"
 	"github.com/disintegration/gift"
 	"github.com/fogleman/gg"
 )
 
 const (
-	mm2pt       = 2.83464 // mm to pt conversion
-	linespacing = 1.4
-	listspacing = 2.0
-	fontfactor  = 1.0
-	listwrap    = 95.0
+	mm2pt = 2.83464 // mm to pt conversion
 )
 
 // PageDimen describes page dimensions
@@ -50,8 +47,6 @@ var pagemap = map[string]PageDimen{
 	"A5":         {210, 148, mm2pt},
 }
 
-var codemap = strings.NewReplacer("\t", "    ")
-
 // pagerange returns the begin and end using a "-" string
 func pagerange(s string) (int, int) {
 	p := strings.Split(s, "-")
@@ -69,24 +64,9 @@ func pagerange(s string) (int, int) {
 	return b, e
 }
 
-// includefile returns the contents of a file as string
-func includefile(filename string) string {
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "%v\n", err)
-		return ""
-	}
-	return codemap.Replace(string(data))
-}
-
-// pct converts percentages to canvas measures
-func pct(p, m float64) float64 {
-	return (p / 100.0) * m
-}
-
-// dimen returns canvas dimensions from percentages
-func dimen(w, h, xp, yp, sp float64) (float64, float64, float64) {
-	return pct(xp, w), pct(100-yp, h), pct(sp, w) * fontfactor
+// pngdoc draws slide elements on a raster image
+type pngdoc struct {
+	doc *gg.Context
 }
 
 // fontlookup maps font aliases to implementation font names
@@ -98,541 +78,231 @@ func fontlookup(s string) string {
 	return "sans"
 }
 
-// grid makes a percentage scale
-func grid(doc *gg.Context, w, h float64, color string, percent float64) {
-	pw := w * (percent / 100)
-	ph := h * (percent / 100)
-	r, g, b := colorlookup(color)
-	doc.SetRGB255(r, g, b)
-	doc.SetLineWidth(0.25)
-	fs := pct(1, w)
-	for x, pl := 0.0, 0.0; x <= w; x += pw {
-		doc.DrawLine(x, 0, x, h)
-		doc.Stroke()
-		if pl > 0 {
-			showtext(doc, x, h-fs, fmt.Sprintf("%.0f", pl), fs, "sans", "center")
-		}
-		pl += percent
+// setcolor sets the current color and opacity
+func (p pngdoc) setcolor(color string, opacity float64) {
+	c, alpha := render.Color(color, opacity)
+	p.doc.SetRGBA255(int(c.R), int(c.G), int(c.B), int(255*alpha))
+}
+
+// fontvariant returns the bold or italic variant of a font file
+// (FiraSans-Regular.ttf -> FiraSans-Bold.ttf), or the font itself if there is none
+func fontvariant(font string, st render.Style) string {
+	var style string
+	switch {
+	case st.Bold && st.Italic:
+		style = "-BoldItalic"
+	case st.Bold:
+		style = "-Bold"
+	case st.Italic:
+		style = "-Italic"
+	default:
+		return font
 	}
-	for y, pl := 0.0, 0.0; y <= h; y += ph {
-		doc.DrawLine(0, y, w, y)
-		doc.Stroke()
-		if pl < 100 {
-			showtext(doc, fs, y+(fs/3), fmt.Sprintf("%.0f", 100-pl), fs, "sans", "center")
-		}
-		pl += percent
+	vf := strings.TrimSuffix(strings.TrimSuffix(font, ".ttf"), "-Regular") + style + ".ttf"
+	if _, err := os.Stat(vf); err != nil {
+		return font
 	}
+	return vf
 }
 
-// setop sets the opacity as a truncated fraction of 255
-func setop(v float64) int {
-	if v > 0.0 {
-		return int(255.0 * (v / 100.0))
+// loadfont loads the font of the style at its size
+func (p pngdoc) loadfont(st render.Style) {
+	f, err := gg.LoadFontFace(fontvariant(fontlookup(st.Font), st), st.Size)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pngdeck %v\n", err)
+		return
 	}
-	return 255
+	p.doc.SetFontFace(f)
 }
 
-// bullet draws a bullet
-func bullet(doc *gg.Context, x, y, size float64, color string) {
-	rs := size / 2
-	r, g, b := colorlookup(color)
-	doc.SetRGB255(r, g, b)
-	doc.DrawCircle(x-size*2, y-rs, rs)
-	doc.Fill()
-}
-
-// background places a colored rectangle
-func background(doc *gg.Context, w, h float64, color string) {
-	r, g, b := colorlookup(color)
-	doc.SetRGB255(r, g, b)
-	doc.Clear()
-}
-
-// gradientbg sets the background color gradient
-func gradientbg(doc *gg.Context, w, h float64, gc1, gc2 string, gp float64) {
-	gradient(doc, 0, 0, w, h, gc1, gc2, gp)
+// linestyle sets the stroke width, dash pattern, line cap and line join;
+// gg has no mitered joins, so lines are joined with round joins unless beveled
+func (p pngdoc) linestyle(s render.Style) {
+	p.doc.SetLineWidth(s.Width)
+	p.doc.SetDash(s.Dash...)
+	switch s.Cap {
+	case "round":
+		p.doc.SetLineCapRound()
+	case "square":
+		p.doc.SetLineCapSquare()
+	default:
+		p.doc.SetLineCapButt()
+	}
+	if s.Join == "bevel" {
+		p.doc.SetLineJoinBevel()
+	} else {
+		p.doc.SetLineJoinRound()
+	}
 }
 
-// gradient sets the rect color gradient
-func gradient(doc *gg.Context, x, y, w, h float64, gc1, gc2 string, gp float64) {
-	r1, g1, b1 := colorlookup(gc1)
-	r2, g2, b2 := colorlookup(gc2)
-	gp /= 100.0
-	grad := gg.NewLinearGradient(x, y, x+w, y+h)
-	grad.AddColorStop(0, color.RGBA{uint8(r1), uint8(g1), uint8(b1), 1})
-	grad.AddColorStop(1, color.RGBA{uint8(r2), uint8(g2), uint8(b2), 1})
-	doc.SetFillStyle(grad)
-	doc.DrawRectangle(x, y, w, h)
-	doc.Fill()
+// gradient returns the pattern of a gradient fill
+func gradient(g *render.Gradient, opacity float64) gg.Gradient {
+	grad := gg.NewLinearGradient(g.X1, g.Y1, g.X2, g.Y2)
+	if g.Radial {
+		grad = gg.NewRadialGradient(g.X1, g.Y1, 0, g.X1, g.Y1, g.R)
+	}
+	for i, gc := range []string{g.Color1, g.Color2} {
+		c, alpha := render.Color(gc, opacity)
+		grad.AddColorStop(float64(i), color.NRGBA{c.R, c.G, c.B, uint8(255 * alpha)})
+	}
+	return grad
 }
 
-// doline draws a line
-func doline(doc *gg.Context, xp1, yp1, xp2, yp2, sw float64, color string, opacity float64) {
-	r, g, b := colorlookup(color)
-	doc.SetLineWidth(sw)
-	doc.SetRGBA255(r, g, b, setop(opacity))
-	doc.SetLineCapButt()
-	doc.DrawLine(xp1, yp1, xp2, yp2)
-	doc.Stroke()
+// paint fills and outlines the current path of a shape
+func (p pngdoc) paint(s render.Style) {
+	switch {
+	case s.NoFill:
+	case s.Gradient != nil:
+		p.doc.SetFillStyle(gradient(s.Gradient, s.Opacity))
+		p.doc.FillPreserve()
+	default:
+		p.setcolor(s.Color, s.Opacity)
+		p.doc.FillPreserve()
+	}
+	if s.Stroke != "" {
+		p.setcolor(s.Stroke, s.Opacity)
+		p.linestyle(s)
+		p.doc.StrokePreserve()
+	}
+	p.doc.ClearPath()
 }
 
-// doarc draws an arc
-func doarc(doc *gg.Context, x, y, w, h, a1, a2, sw float64, color string, opacity float64) {
-	r, g, b := colorlookup(color)
-	doc.SetLineWidth(sw)
-	doc.SetRGBA255(r, g, b, setop(opacity))
-	doc.SetLineCapButt()
-	doc.DrawEllipticalArc(x, y, w, h, gg.Radians(360-a1), gg.Radians(360-a2))
-	doc.Stroke()
+// Rect draws a rectangle
+func (p pngdoc) Rect(x, y, w, h float64, s render.Style) {
+	p.doc.DrawRectangle(x, y, w, h)
+	p.paint(s)
 }
 
-// docurve draws a bezier curve
-func docurve(doc *gg.Context, xp1, yp1, xp2, yp2, xp3, yp3, sw float64, color string, opacity float64) {
-	r, g, b := colorlookup(color)
-	doc.SetLineWidth(sw)
-	doc.SetLineCapButt()
-	doc.SetRGBA255(r, g, b, setop(opacity))
-	doc.MoveTo(xp1, yp1)
-	doc.QuadraticTo(xp2, yp2, xp3, yp3)
-	doc.Stroke()
+// Ellipse draws an ellipse
+func (p pngdoc) Ellipse(x, y, w, h float64, s render.Style) {
+	p.doc.DrawEllipse(x, y, w, h)
+	p.paint(s)
 }
 
-// dorect draws a rectangle
-func dorect(doc *gg.Context, x, y, w, h float64, color string, opacity float64) {
-	r, g, b := colorlookup(color)
-	doc.SetRGBA255(r, g, b, setop(opacity))
-	doc.DrawRectangle(x, y, w, h)
-	doc.Fill()
+// Arc draws an arc
+func (p pngdoc) Arc(x, y, w, h, a1, a2 float64, s render.Style) {
+	p.setcolor(s.Color, s.Opacity)
+	p.linestyle(s)
+	p.doc.DrawEllipticalArc(x, y, w, h, gg.Radians(360-a1), gg.Radians(360-a2))
+	p.doc.Stroke()
 }
 
-// doellipse draws a rectangle
-func doellipse(doc *gg.Context, x, y, w, h float64, color string, opacity float64) {
-	r, g, b := colorlookup(color)
-	doc.SetRGBA255(r, g, b, setop(opacity))
-	doc.DrawEllipse(x, y, w, h)
-	doc.Fill()
+// Curve draws a quadratic bezier curve
+func (p pngdoc) Curve(x1, y1, x2, y2, x3, y3 float64, s render.Style) {
+	p.setcolor(s.Color, s.Opacity)
+	p.linestyle(s)
+	p.doc.MoveTo(x1, y1)
+	p.doc.QuadraticTo(x2, y2, x3, y3)
+	p.doc.Stroke()
 }
 
-// dopoly draws a polygon
-func dopoly(doc *gg.Context, xc, yc string, cw, ch float64, color string, opacity float64) {
-	xs := strings.Split(xc, " ")
-	ys := strings.Split(yc, " ")
-	if len(xs) != len(ys) {
-		return
-	}
-	if len(xs) < 3 || len(ys) < 3 {
-		return
-	}
-	doc.NewSubPath()
-	for i := 0; i < len(xs); i++ {
-		x, err := strconv.ParseFloat(xs[i], 64)
-		if err != nil {
-			x = 0
-		} else {
-			x = pct(x, cw)
-		}
-		y, err := strconv.ParseFloat(ys[i], 64)
-		if err != nil {
-			y = 0
-		} else {
-			y = pct(100-y, ch)
-		}
-		doc.LineTo(x, y)
-	}
-	doc.ClosePath()
-	r, g, b := colorlookup(color)
-	doc.SetRGBA255(r, g, b, setop(opacity))
-	doc.Fill()
+// Line draws a line
+func (p pngdoc) Line(x1, y1, x2, y2 float64, s render.Style) {
+	p.setcolor(s.Color, s.Opacity)
+	p.linestyle(s)
+	p.doc.DrawLine(x1, y1, x2, y2)
+	p.doc.Stroke()
 }
 
-// dotext places text elements on the canvas according to type
-func dotext(doc *gg.Context, cw, x, y, fs, wp, rotation, spacing float64, tdata, font, align, ttype, color string, opacity float64, strict bool) {
-	var tw float64
-
-	td := strings.Split(tdata, "\n")
-	red, green, blue := colorlookup(color)
-	if rotation > 0 {
-		doc.Push()
-		doc.RotateAbout(gg.Radians(360-rotation), x, y)
-	}
-	if ttype == "code" {
-		font = "mono"
-		ch := float64(len(td)) * spacing * fs
-		tw = deck.Pwidth(wp, cw, cw-x-20)
-		dorect(doc, x-fs, y-fs, tw, ch, "rgb(240,240,240)", 100)
-	}
-	doc.SetRGBA255(red, green, blue, setop(opacity))
-	if ttype == "block" {
-		tw = deck.Pwidth(wp, cw, cw/2)
-		textwrap(doc, x, y, tw, fs, fs*spacing, tdata, font, strict)
-	} else {
-		ls := spacing * fs
-		for _, t := range td {
-			showtext(doc, x, y, t, fs, font, align)
-			y += ls
-		}
+// Polygon draws a polygon
+func (p pngdoc) Polygon(x, y []float64, s render.Style) {
+	p.doc.NewSubPath()
+	for i := range x {
+		p.doc.LineTo(x[i], y[i])
 	}
-	if rotation > 0 {
-		doc.Pop()
-	}
-}
-
-// whitespace determines if a rune is whitespace
-func whitespace(r rune) bool {
-	return r == ' ' || r == '\n' || r == '\t'
+	p.doc.ClosePath()
+	p.paint(s)
 }
 
-// loadfont loads a font at the specified size
-func loadfont(doc *gg.Context, s string, size float64) {
-	f, err := gg.LoadFontFace(fontlookup(s), size)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "pngdeck %v\n", err)
-		return
+// Polyline draws connected line segments
+func (p pngdoc) Polyline(x, y []float64, s render.Style) {
+	p.doc.NewSubPath()
+	for i := range x {
+		p.doc.LineTo(x[i], y[i])
 	}
-	doc.SetFontFace(f)
+	p.setcolor(s.Color, s.Opacity)
+	p.linestyle(s)
+	p.doc.Stroke()
 }
 
-// textwrap draws text at location, wrapping at the specified width
-func textwrap(doc *gg.Context, x, y, w, fs, leading float64, s, font string, strict bool) int {
-	var factor = 0.3
-	if font == "mono" {
-		factor = 1.0
-	}
-	nbreak := 0
-	loadfont(doc, font, fs)
-	wordspacing, _ := doc.MeasureString("M")
-	words := strings.FieldsFunc(s, whitespace)
-	xp := x
-	yp := y
-	edge := x + w
-	for _, s := range words {
-		if s == "\\n" { // magic new line
-			xp = x
-			yp += (leading * 1.5)
-			nbreak++
-			continue
-		}
-		if strict {
-			tw, _ := doc.MeasureString(s)
-			if xp+tw > edge {
-				xp = x
-				yp += leading
-				nbreak++
-			}
-			doc.DrawString(s, xp, yp)
-			xp += tw + (wordspacing * factor)
-		} else {
-			tw, _ := doc.MeasureString(s)
-			doc.DrawString(s, xp, yp)
-			xp += tw + (wordspacing * factor)
-			if xp > edge {
-				xp = x
-				yp += leading
-				nbreak++
-			}
+// Path draws a path
+func (p pngdoc) Path(ops []render.PathOp, s render.Style) {
+	for _, op := range ops {
+		switch op.Op {
+		case 'M':
+			p.doc.MoveTo(op.X[0], op.Y[0])
+		case 'L':
+			p.doc.LineTo(op.X[0], op.Y[0])
+		case 'Q':
+			p.doc.QuadraticTo(op.X[0], op.Y[0], op.X[1], op.Y[1])
+		case 'C':
+			p.doc.CubicTo(op.X[0], op.Y[0], op.X[1], op.Y[1], op.X[2], op.Y[2])
+		case 'Z':
+			p.doc.ClosePath()
 		}
 	}
-	return nbreak
+	p.paint(s)
 }
 
-// showtext places fully attributed text at the specified location
-func showtext(doc *gg.Context, x, y float64, s string, fs float64, font, align string) {
+// Text places fully attributed text at the specified location
+func (p pngdoc) Text(x, y float64, s string, st render.Style) {
 	offset := 0.0
-	loadfont(doc, font, fs)
-	t := s
-	tw, _ := doc.MeasureString(t)
-	switch align {
+	p.loadfont(st)
+	p.setcolor(st.Color, st.Opacity)
+	tw, _ := p.doc.MeasureString(s)
+	switch st.Align {
 	case "center", "middle", "mid", "c":
 		offset = (tw / 2)
 	case "right", "end", "e":
 		offset = tw
 	}
-	doc.DrawString(t, x-offset, y)
+	p.doc.DrawString(s, x-offset, y)
 }
 
-// dolists places lists on the canvas
-func dolist(doc *gg.Context, cw, x, y, fs, lwidth, rotation, spacing float64, list []deck.ListItem, font, ltype, align, color string, opacity float64) {
-	if font == "" {
-		font = "sans"
-	}
-	red, green, blue := colorlookup(color)
-
-	if ltype == "bullet" {
-		x += fs * 1.2
-	}
-	ls := spacing * fs
-	tw := deck.Pwidth(lwidth, cw, cw/2)
-
-	if rotation > 0 {
-		doc.Push()
-		doc.RotateAbout(gg.Radians(360-rotation), x, y)
-	}
-	var t string
-	for i, tl := range list {
-		loadfont(doc, font, fs)
-		doc.SetRGBA255(red, green, blue, setop(tl.Opacity))
-		if ltype == "number" {
-			t = fmt.Sprintf("%d. ", i+1) + tl.ListText
-		} else {
-			t = tl.ListText
-		}
-		if ltype == "bullet" {
-			bullet(doc, x, y, fs/2, color)
-		}
-		if len(tl.Color) > 0 {
-			tlred, tlgreen, tlblue := colorlookup(tl.Color)
-			doc.SetRGB255(tlred, tlgreen, tlblue)
-		}
-		if len(tl.Font) > 0 {
-			loadfont(doc, tl.Font, fs)
-		}
-		if align == "center" || align == "c" {
-			showtext(doc, x, y, t, fs, font, align)
-			y += ls
-		} else {
-			yw := textwrap(doc, x, y, tw, fs, ls, t, font, false)
-			y += ls
-			if yw >= 1 {
-				y += ls * float64(yw)
-			}
-		}
-	}
-	if rotation > 0 {
-		doc.Pop()
-	}
+// TextWidth returns the width of text
+func (p pngdoc) TextWidth(s string, st render.Style) float64 {
+	p.loadfont(st)
+	tw, _ := p.doc.MeasureString(s)
+	return tw
 }
 
-// pngslide makes a slide, one slide per generated PNG
-func pngslide(doc *gg.Context, d deck.Deck, n int, gp float64, strict bool, showslide bool, layers string, dest string) {
-	if n < 0 || n > len(d.Slide)-1 || !showslide {
+// Image places an image centered at (x,y), resizing if needed
+func (p pngdoc) Image(x, y, w, h float64, name string, s render.Style) {
+	img, err := gg.LoadImage(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pngdeck: %v\n", err)
 		return
 	}
+	iw, ih := int(w), int(h)
+	bounds := img.Bounds()
+	if iw == bounds.Dx() && ih == bounds.Dy() {
+		p.doc.DrawImageAnchored(img, int(x), int(y), 0.5, 0.5)
+		return
+	}
+	g := gift.New(gift.Resize(iw, ih, gift.BoxResampling))
+	resized := image.NewRGBA(g.Bounds(img.Bounds()))
+	g.Draw(resized, img)
+	p.doc.DrawImageAnchored(resized, int(x), int(y), 0.5, 0.5)
+}
 
-	var x, y, fs float64
+// Rotate begins a rotation about (x,y)
+func (p pngdoc) Rotate(x, y, angle float64) {
+	p.doc.Push()
+	p.doc.RotateAbout(gg.Radians(360-angle), x, y)
+}
 
-	cw := float64(d.Canvas.Width)
-	ch := float64(d.Canvas.Height)
-	slide := d.Slide[n]
-	// set default background
-	if slide.Bg == "" {
-		slide.Bg = "white"
-	}
-	background(doc, cw, ch, slide.Bg)
+// EndRotate ends a rotation
+func (p pngdoc) EndRotate() {
+	p.doc.Pop()
+}
 
-	if slide.GradPercent <= 0 || slide.GradPercent > 100 {
-		slide.GradPercent = 100
-	}
-	// set gradient background, if specified. You need both colors
-	if len(slide.Gradcolor1) > 0 && len(slide.Gradcolor2) > 0 {
-		gradientbg(doc, cw, ch, slide.Gradcolor1, slide.Gradcolor2, slide.GradPercent)
-	}
-	// set the default foreground
-	if slide.Fg == "" {
-		slide.Fg = "black"
-	}
-	const defaultColor = "rgb(127,127,127)"
-	layerlist := strings.Split(layers, ":")
-	// draw elements in the order of the layer list
-	for il := 0; il < len(layerlist); il++ {
-		switch layerlist[il] {
-		// for every image on the slide...
-		case "image":
-			for _, im := range slide.Image {
-				x, y, _ = dimen(cw, ch, im.Xp, im.Yp, 0)
-				iw, ih := im.Width, im.Height
-				// scale the image by the specified percentage
-				if im.Scale > 0 {
-					iw = int(float64(iw) * (im.Scale / 100))
-					ih = int(float64(ih) * (im.Scale / 100))
-				}
-				// scale the image to fit the canvas width
-				if im.Autoscale == "on" && iw < d.Canvas.Width {
-					ih = int((float64(d.Canvas.Width) / float64(iw)) * float64(ih))
-					iw = d.Canvas.Width
-				}
-
-				img, err := gg.LoadImage(im.Name)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "pngdeck: slide %d (%v)\n", n+1, err)
-					return
-				}
-
-				bounds := img.Bounds()
-				nw, nh := bounds.Max.X-bounds.Min.X, bounds.Max.Y-bounds.Min.Y
-				// scale the image to a percentage of the canvas width
-				if im.Height == 0 && im.Width > 0 {
-					if nh > 0 {
-						fw := float64(im.Width)
-						imscale := (fw / 100) * cw
-						fw = imscale
-						fh := imscale / (float64(nw) / float64(nh))
-						iw = int(fw)
-						ih = int(fh)
-					}
-				}
-
-				if iw == nw && ih == nh {
-					doc.DrawImageAnchored(img, int(x), int(y), 0.5, 0.5)
-				} else {
-					g := gift.New(gift.Resize(iw, ih, gift.BoxResampling))
-					resized := image.NewRGBA(g.Bounds(img.Bounds()))
-					g.Draw(resized, img)
-					doc.DrawImageAnchored(resized, int(x), int(y), 0.5, 0.5)
-				}
-				if len(im.Caption) > 0 {
-					capsize := deck.Pwidth(im.Sp, cw, pct(2, cw))
-					if im.Font == "" {
-						im.Font = "sans"
-					}
-					if im.Color == "" {
-						im.Color = slide.Fg
-					}
-					if im.Align == "" {
-						im.Align = "center"
-					}
-					midx := float64(iw) / 2
-					midy := float64(ih) / 2
-					switch im.Align {
-					case "left", "start":
-						x -= midx
-					case "right", "end":
-						x += midx
-					}
-					capr, capg, capb := colorlookup(im.Color)
-					doc.SetRGB255(capr, capg, capb)
-					showtext(doc, x, y+(midy)+(capsize*1.5), im.Caption, capsize, im.Font, im.Align)
-				}
-			}
-		// every graphic on the slide
-		case "rect":
-
-			// rect
-			for _, rect := range slide.Rect {
-				x, y, _ := dimen(cw, ch, rect.Xp, rect.Yp, 0)
-				var w, h float64
-				w = pct(rect.Wp, cw)
-				if rect.Hr == 0 {
-					h = pct(rect.Hp, ch)
-				} else {
-					h = pct(rect.Hr, w)
-				}
-				if rect.Color == "" {
-					rect.Color = defaultColor
-				}
-				if len(rect.Gradcolor1) > 0 && len(rect.Gradcolor2) > 0 {
-					gradient(doc, x-(w/2), y-(h/2), w, h, rect.Gradcolor1, rect.Gradcolor2, rect.GradPercent)
-				} else {
-					dorect(doc, x-(w/2), y-(h/2), w, h, rect.Color, rect.Opacity)
-				}
-			}
-		case "ellipse":
-			// ellipse
-			for _, ellipse := range slide.Ellipse {
-				x, y, _ := dimen(cw, ch, ellipse.Xp, ellipse.Yp, 0)
-				var w, h float64
-				w = pct(ellipse.Wp, cw)
-				if ellipse.Hr == 0 {
-					h = pct(ellipse.Hp, ch)
-				} else {
-					h = pct(ellipse.Hr, w)
-				}
-				if ellipse.Color == "" {
-					ellipse.Color = defaultColor
-				}
-				doellipse(doc, x, y, w/2, h/2, ellipse.Color, ellipse.Opacity)
-			}
-		case "curve":
-			// curve
-			for _, curve := range slide.Curve {
-				if curve.Color == "" {
-					curve.Color = defaultColor
-				}
-				x1, y1, sw := dimen(cw, ch, curve.Xp1, curve.Yp1, curve.Sp)
-				x2, y2, _ := dimen(cw, ch, curve.Xp2, curve.Yp2, 0)
-				x3, y3, _ := dimen(cw, ch, curve.Xp3, curve.Yp3, 0)
-				if sw == 0 {
-					sw = 2.0
-				}
-				docurve(doc, x1, y1, x2, y2, x3, y3, sw, curve.Color, curve.Opacity)
-			}
-		case "arc":
-			// arc
-			for _, arc := range slide.Arc {
-				if arc.Color == "" {
-					arc.Color = defaultColor
-				}
-				x, y, sw := dimen(cw, ch, arc.Xp, arc.Yp, arc.Sp)
-				w := pct(arc.Wp, cw)
-				h := pct(arc.Hp, cw)
-				if sw == 0 {
-					sw = 2.0
-				}
-				doarc(doc, x, y, w/2, h/2, arc.A1, arc.A2, sw, arc.Color, arc.Opacity)
-			}
-		case "line":
-			// line
-			for _, line := range slide.Line {
-				if line.Color == "" {
-					line.Color = defaultColor
-				}
-				x1, y1, sw := dimen(cw, ch, line.Xp1, line.Yp1, line.Sp)
-				x2, y2, _ := dimen(cw, ch, line.Xp2, line.Yp2, 0)
-				if sw == 0 {
-					sw = 2.0
-				}
-				doline(doc, x1, y1, x2, y2, sw, line.Color, line.Opacity)
-			}
-		case "poly":
-			// polygon
-			for _, poly := range slide.Polygon {
-				if poly.Color == "" {
-					poly.Color = defaultColor
-				}
-				dopoly(doc, poly.XC, poly.YC, cw, ch, poly.Color, poly.Opacity)
-			}
-		case "text":
-			// for every text element...
-			var tdata string
-			for _, t := range slide.Text {
-				if t.Color == "" {
-					t.Color = slide.Fg
-				}
-				if t.Font == "" {
-					t.Font = "sans"
-				}
-				x, y, fs = dimen(cw, ch, t.Xp, t.Yp, t.Sp)
-				if t.File != "" {
-					tdata = includefile(t.File)
-				} else {
-					tdata = t.Tdata
-				}
-				if t.Lp == 0 {
-					t.Lp = linespacing
-				}
-				dotext(doc, cw, x, y, fs, t.Wp, t.Rotation, t.Lp, tdata, t.Font, t.Align, t.Type, t.Color, t.Opacity, strict)
-			}
-		case "list":
-			// for every list element...
-			for _, l := range slide.List {
-				if l.Color == "" {
-					l.Color = slide.Fg
-				}
-				if l.Lp == 0 {
-					l.Lp = listspacing
-				}
-				if l.Wp == 0 {
-					l.Wp = listwrap
-				}
-				x, y, fs = dimen(cw, ch, l.Xp, l.Yp, l.Sp)
-				dolist(doc, cw, x, y, fs, l.Wp, l.Rotation, l.Lp, l.Li, l.Font, l.Type, l.Align, l.Color, l.Opacity)
-			}
-		}
-	}
-	// add a grid, if specified
-	if gp > 0 {
-		grid(doc, cw, ch, slide.Fg, gp)
+// pngslide makes a build step of a slide, one per generated PNG, numbered by page
+func pngslide(doc *gg.Context, d deck.Deck, n, step, page int, gp float64, strict bool, showslide bool, layers string, dest string) {
+	if n < 0 || n > len(d.Slide)-1 || !showslide {
+		return
 	}
-	doc.SavePNG(fmt.Sprintf("%s-%05d.png", dest, n+1))
+	render.Slide(pngdoc{doc}, d, n, render.Options{Layers: layers, Grid: gp, StrictWrap: strict, Step: step})
+	doc.SavePNG(fmt.Sprintf("%s-%05d.png", dest, page))
 }
 
 // doslides reads the deck file, making a series of PNGs
@@ -647,8 +317,13 @@ func doslides(outname, filename string, w, h int, gp float64, layers string, str
 	d.Canvas.Width = w
 	d.Canvas.Height = h
 
+	// pages are numbered in sequence, one for each build step of each slide
+	page := 0
 	for i := 0; i < len(d.Slide); i++ {
-		pngslide(gg.NewContext(w, h), d, i, gp, strict, (i+1 >= begin && i+1 <= end), layers, outname)
+		for step := 1; step <= deck.Steps(d.Slide[i]); step++ {
+			page++
+			pngslide(gg.NewContext(w, h), d, i, step, page, gp, strict, (i+1 >= begin && i+1 <= end), layers, outname)
+		}
 	}
 }
 
@@ -656,8 +331,8 @@ func doslides(outname, filename string, w, h int, gp float64, layers string, str
 // PNGs are written to the destination directory, to filenames based on the input name.
 func dodeck(files []string, w, h float64, outdir string, gp float64, layers string, strict bool, begin, end int) {
 	for _, filename := range files {
-		base := strings.Split(filepath.Base(filename), ".xml")
-		outname := filepath.Join(outdir, base[0])
+		base := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+		outname := filepath.Join(outdir, base)
 		doslides(outname, filename, int(w), int(h), gp, layers, strict, begin, end)
 	}
 }
@@ -706,7 +381,8 @@ Options     Default                                            Description
 -mono       FiraMono-Regular                                   Monospace font
 -symbol     ZapfDingbats                                       Symbol font
 
--layers     image:rect:ellipse:curve:arc:line:poly:text:list   Drawing order
+-layers     image:rect:ellipse:curve:arc:line:poly:            Drawing order
+            polyline:path:connector:table:chart:text:list:group
 -grid       0                                                  Draw a grid at specified %
 -pages      1-1000000                                          Pages to output (first-last)
 -pagesize   Letter                                             Page size (w,h) or Letter, Legal,
@@ -729,7 +405,7 @@ func main() {
 		monofont   = flag.String("mono", "FiraMono-Regular", "mono font")
 		symbolfont = flag.String("symbol", "ZapfDingbats", "symbol font")
 		pagesize   = flag.String("pagesize", "Letter", "pagesize: w,h, or one of: Letter, Legal, Tabloid, A3, A4, A5, ArchA, 4R, Index, Widescreen")
-		layers     = flag.String("layers", "image:rect:ellipse:curve:arc:line:poly:text:list", "Drawing order")
+		layers     = flag.String("layers", "image:rect:ellipse:curve:arc:line:poly:polyline:path:connector:table:chart:text:list:group", "Drawing order")
 		fontdir    = flag.String("fontdir", setfontdir(""), "directory for fonts")
 		outdir     = flag.String("outdir", ".", "output directory")
 		gridpct    = flag.Float64("grid", 0, "draw a percentage grid on each slide")
@@ -15,8 +15,8 @@ The -g (grid) option specified te scale of the x-ray grid.
 The loop option pauses the specified duration between slides. If loop is not specified, then vgdeck enters
 an interactive mode using these commands:
 
-      Next slide: +, Ctrl-N, [Return]
-      Previous slide, -, Ctrl-P, [Backspace]
+      Next build step or slide: +, Ctrl-N, [Return]
+      Previous build step or slide, -, Ctrl-P, [Backspace]
       First slide: ^, Ctrl-A
       Last slide: $, Ctrl-E
       Reload: r, Ctrl-R
@@ -10,20 +10,17 @@ import (
 	"image"
 	_ "image/jpeg"
 	_ "image/png"
-	"io/ioutil"
 	"os"
-	"strconv"
-	"strings"
 	"time"
 
 	"github.com/ajstarks/deck"
+	"github.com/ajstarks/deck/render"
 	"github.com/ajstarks/openvg"
 	"github.com/disintegration/gift"
 )
 
 var StartTime = time.Now()
 var firstrun int
-var codemap = strings.NewReplacer("\t", "    ")
 
 // dodeck sets up the graphics environment and kicks off the interaction
 func dodeck(filename, searchterm string, pausetime time.Duration, slidenum, cw, ch int, gp float64) {
@@ -140,10 +137,35 @@ func interact(filename, searchterm string, w, h, slidenum int, gp float64) {
 			slidenum = sr
 		}
 	}
-	n := slidenum
+	n, step := slidenum, 1
 	xray := 1
 	initial := 0
 	imap := make(map[string]image.Image)
+	// next shows the next build step, or the next slide;
+	// prev shows the previous build step, or the previous slide, fully built
+	next := func() {
+		if step < steps(d, n) {
+			step++
+		} else {
+			n, step = n+1, 1
+			if n > lastslide {
+				n = 0
+			}
+		}
+		showslide(d, imap, n, step)
+	}
+	prev := func() {
+		if step > 1 {
+			step--
+		} else {
+			n--
+			if n < 0 {
+				n = lastslide
+			}
+			step = steps(d, n)
+		}
+		showslide(d, imap, n, step)
+	}
 	// respond to keyboard commands, 'q' to exit
 	for cmd := byte('0'); cmd != 'q'; cmd = readcmd(r) {
 		switch cmd {
@@ -157,7 +179,7 @@ func interact(filename, searchterm string, w, h, slidenum int, gp float64) {
 			loadimage(d, imap)
 			openvg.Background(0, 0, 0)
 			xray = 1
-			showslide(d, imap, n)
+			showslide(d, imap, n, step)
 
 		// save slide
 		case 's', 19: // s, Ctrl-S
@@ -172,33 +194,26 @@ func interact(filename, searchterm string, w, h, slidenum int, gp float64) {
 			} else {
 				n = 0
 			}
-			showslide(d, imap, n)
+			step = 1
+			showslide(d, imap, n, step)
 
 		// last slide
 		case '*', 5, '$': // *, Crtl-E, $
-			n = lastslide
-			showslide(d, imap, n)
+			n, step = lastslide, 1
+			showslide(d, imap, n, step)
 
-		// next slide
+		// next build step or slide
 		case '+', 'n', '\n', ' ', '\t', '=', 14: // +,n,newline,space,tab,equal,Crtl-N
-			n++
-			if n > lastslide {
-				n = 0
-			}
-			showslide(d, imap, n)
+			next()
 
-		// previous slide
+		// previous build step or slide
 		case '-', 'p', 8, 16, 127: // -,p,Backspace,Ctrl-P,Del
-			n--
-			if n < 0 {
-				n = lastslide
-			}
-			showslide(d, imap, n)
+			prev()
 
 		// x-ray
 		case 'x', 24: // x, Ctrl-X
 			xray++
-			showslide(d, imap, n)
+			showslide(d, imap, n, step)
 			if xray%2 == 0 {
 				showgrid(d, n, gp)
 			}
@@ -214,17 +229,9 @@ func interact(filename, searchterm string, w, h, slidenum int, gp float64) {
 					openvg.End()
 
 				case '5': // back
-					n--
-					if n < 0 {
-						n = lastslide
-					}
-					showslide(d, imap, n)
+					prev()
 				case '6': // forward
-					n++
-					if n > lastslide {
-						n = 0
-					}
-					showslide(d, imap, n)
+					next()
 				}
 			}
 		// search
@@ -238,8 +245,8 @@ func interact(filename, searchterm string, w, h, slidenum int, gp float64) {
 			if len(searchterm) > 2 {
 				ns := deck.Search(d, searchterm[0:len(searchterm)-1])
 				if ns >= 0 {
-					showslide(d, imap, ns)
-					n = ns
+					n, step = ns, 1
+					showslide(d, imap, n, step)
 				}
 			}
 		}
@@ -273,7 +280,7 @@ func loop(filename string, w, h, slidenum int, n time.Duration) {
 			if readcmd(r) == 'q' {
 				return
 			}
-			showslide(d, imap, i)
+			showslide(d, imap, i, 0)
 			pd, err := time.ParseDuration(d.Slide[i].Duration)
 			if err != nil {
 				sd = n
@@ -290,12 +297,6 @@ func pct(p float64, m openvg.VGfloat) openvg.VGfloat {
 	return openvg.VGfloat((p / 100.0)) * m
 }
 
-func pctwidth(p float64, p1, p2 openvg.VGfloat) openvg.VGfloat {
-	pw := deck.Pwidth(p, float64(p1), float64(p2))
-	return openvg.VGfloat(pw)
-
-}
-
 // showgrid xrays a slide
 func showgrid(d deck.Deck, n int, p float64) {
 	w := openvg.VGfloat(d.Canvas.Width)
@@ -345,383 +346,220 @@ func showgrid(d deck.Deck, n int, p float64) {
 	openvg.End()
 }
 
-//showtext displays text
-func showtext(x, y openvg.VGfloat, t, align, font string, fs openvg.VGfloat) {
-	//t := s // fromUTF8(s)
-	fontsize := int(fs)
-	switch align {
-	case "center", "middle", "mid", "c":
-		openvg.TextMid(x, y, t, font, fontsize)
-	case "right", "end", "e":
-		openvg.TextEnd(x, y, t, font, fontsize)
-	default:
-		openvg.Text(x, y, t, font, fontsize)
-	}
+// vgdoc draws slide elements using OpenVG;
+// y coordinates are flipped, since OpenVG has its origin at the lower left.
+type vgdoc struct {
+	imap map[string]image.Image
+	ch   openvg.VGfloat
 }
 
-// dimen returns device dimemsion from percentages
-func dimen(d deck.Deck, x, y, s float64) (xo, yo, so openvg.VGfloat) {
-	xf, yf, sf := deck.Dimen(d.Canvas, x, y, s)
-	xo, yo, so = openvg.VGfloat(xf), openvg.VGfloat(yf), openvg.VGfloat(sf)*0.8
-	return
+// textsize is the point size of text
+func textsize(s render.Style) int {
+	return int(s.Size * 0.8)
 }
 
-// showlide displays slides
-func showslide(d deck.Deck, imap map[string]image.Image, n int) {
-	if n < 0 || n > len(d.Slide)-1 {
+// fill sets the fill color and opacity, or the gradient of the style
+func (p vgdoc) fill(s render.Style) {
+	if g := s.Gradient; g != nil {
+		oc := make([]openvg.Offcolor, 2)
+		for i, gc := range []string{g.Color1, g.Color2} {
+			c, alpha := render.Color(gc, s.Opacity)
+			oc[i] = openvg.Offcolor{Offset: openvg.VGfloat(i), RGB: openvg.RGB{Red: c.R, Green: c.G, Blue: c.B}, Alpha: openvg.VGfloat(alpha)}
+		}
+		x1, y1, x2, y2 := openvg.VGfloat(g.X1), p.ch-openvg.VGfloat(g.Y1), openvg.VGfloat(g.X2), p.ch-openvg.VGfloat(g.Y2)
+		if g.Radial {
+			openvg.FillRadialGradient(x1, y1, x1, y1, openvg.VGfloat(g.R), oc)
+			return
+		}
+		openvg.FillLinearGradient(x1, y1, x2, y2, oc)
 		return
 	}
-	slide := d.Slide[n]
-	if slide.Bg == "" {
-		slide.Bg = "white"
+	c, alpha := render.Color(s.Color, s.Opacity)
+	openvg.FillRGB(c.R, c.G, c.B, openvg.VGfloat(alpha))
+}
+
+// Rect draws a rectangle
+func (p vgdoc) Rect(x, y, w, h float64, s render.Style) {
+	if !s.NoFill {
+		p.fill(s)
+		openvg.Rect(openvg.VGfloat(x), p.ch-openvg.VGfloat(y+h), openvg.VGfloat(w), openvg.VGfloat(h))
 	}
-	if slide.Fg == "" {
-		slide.Fg = "black"
+	p.outline([]float64{x, x + w, x + w, x, x}, []float64{y, y, y + h, y + h, y}, s)
+}
+
+// Ellipse draws an ellipse
+func (p vgdoc) Ellipse(x, y, w, h float64, s render.Style) {
+	if !s.NoFill {
+		p.fill(s)
+		openvg.Ellipse(openvg.VGfloat(x), p.ch-openvg.VGfloat(y), openvg.VGfloat(w*2), openvg.VGfloat(h*2))
 	}
-	openvg.Start(d.Canvas.Width, d.Canvas.Height)
-	cw := openvg.VGfloat(d.Canvas.Width)
-	ch := openvg.VGfloat(d.Canvas.Height)
-	if slide.Gradcolor1 != "" && slide.Gradcolor2 != "" {
-		oc := []openvg.Offcolor{
-			{0, openvg.Colorlookup(slide.Gradcolor1), 1},
-			{1, openvg.Colorlookup(slide.Gradcolor2), 1},
-		}
-		openvg.FillLinearGradient(0, ch, 0, 0, oc)
-	} else {
-		openvg.FillColor(slide.Bg)
+	px, py := render.ArcPoints(x, y, w, h, 0, 360)
+	p.outline(px, py, s)
+}
+
+// outline strokes the closed outline of a shape through the points in x and y
+func (p vgdoc) outline(x, y []float64, s render.Style) {
+	if s.Stroke == "" {
+		return
 	}
-	openvg.Rect(0, 0, cw, ch)
-	var x, y, fs openvg.VGfloat
+	s.Color = s.Stroke
+	p.Polyline(x, y, s)
+}
 
-	// every image in the slide
-	for _, im := range slide.Image {
-		x = pct(im.Xp, cw)
-		y = pct(im.Yp, ch)
-		imw := openvg.VGfloat(im.Width)
-		imh := openvg.VGfloat(im.Height)
-		if im.Scale > 0 {
-			imw *= openvg.VGfloat(im.Scale / 100)
-			imh *= openvg.VGfloat(im.Scale / 100)
-		}
-		midx := openvg.VGfloat(imw / 2)
-		midy := openvg.VGfloat(imh / 2)
-		img, ok := imap[im.Name]
-		if ok {
-			openvg.Img(x-midx, y-midy, img)
-		}
-		if len(im.Caption) > 0 {
-			capfs := pctwidth(im.Sp, cw, cw/100)
-			if im.Font == "" {
-				im.Font = "sans"
-			}
-			if im.Color == "" {
-				openvg.FillColor(slide.Fg)
-			} else {
-				openvg.FillColor(im.Color)
-			}
-			if im.Align == "" {
-				im.Align = "center"
-			}
-			switch im.Align {
-			case "left", "start":
-				x -= midx
-			case "right", "end":
-				x += midx
-			}
-			showtext(x, y-((midy)+(capfs*2.0)), im.Caption, im.Align, im.Font, capfs)
-		}
+// dashes strokes the dashes of connected line segments
+func (p vgdoc) dashes(x, y []float64, s render.Style) {
+	dx, dy := render.Dashes(x, y, s.Dash)
+	p.stroke(s)
+	for i := range dx {
+		px, py := p.vgcoords(dx[i], dy[i])
+		openvg.Polyline(px, py)
 	}
+	openvg.StrokeWidth(0)
+}
 
-	// every graphic on the slide
-	const defaultColor = "rgb(127,127,127)"
-	const defaultSw = 1.5
-	var strokeopacity float64
-	// line
-	for _, line := range slide.Line {
-		if line.Color == "" {
-			line.Color = slide.Fg // defaultColor
-		}
-		if line.Opacity == 0 {
-			strokeopacity = 1
-		} else {
-			strokeopacity = line.Opacity / 100.0
-		}
-		x1, y1, sw := dimen(d, line.Xp1, line.Yp1, line.Sp)
-		x2, y2, _ := dimen(d, line.Xp2, line.Yp2, 0)
-		if sw == 0 {
-			sw = defaultSw
-		}
-		openvg.StrokeWidth(openvg.VGfloat(sw))
-		openvg.StrokeColor(line.Color, openvg.VGfloat(strokeopacity))
-		openvg.Line(x1, y1, x2, y2)
-		openvg.StrokeWidth(0)
-	}
-	// ellipse
-	for _, ellipse := range slide.Ellipse {
-		x, y, _ = dimen(d, ellipse.Xp, ellipse.Yp, 0)
-		var w, h openvg.VGfloat
-		w = pct(ellipse.Wp, cw)
-		if ellipse.Hr == 0 { // if relative height not specified, base height on overall height
-			h = pct(ellipse.Hp, ch)
-		} else {
-			h = pct(ellipse.Hr, w)
-		}
-		if ellipse.Color == "" {
-			ellipse.Color = defaultColor
-		}
-		if ellipse.Opacity == 0 {
-			ellipse.Opacity = 1
-		} else {
-			ellipse.Opacity /= 100
-		}
-		openvg.FillColor(ellipse.Color, openvg.VGfloat(ellipse.Opacity))
-		openvg.Ellipse(x, y, w, h)
-	}
-	// rect
-	for _, rect := range slide.Rect {
-		x, y, _ = dimen(d, rect.Xp, rect.Yp, 0)
-		var w, h openvg.VGfloat
-		w = pct(rect.Wp, cw)
-		if rect.Hr == 0 { // if relative height not specified, base height on overall height
-			h = pct(rect.Hp, ch)
-		} else {
-			h = pct(rect.Hr, w)
-		}
-		if rect.Color == "" {
-			rect.Color = defaultColor
-		}
-		if rect.Opacity == 0 {
-			rect.Opacity = 1
-		} else {
-			rect.Opacity /= 100
-		}
-		openvg.FillColor(rect.Color, openvg.VGfloat(rect.Opacity))
-		openvg.Rect(x-(w/2), y-(h/2), w, h)
+// stroke sets the stroke attributes, with a transparent fill
+func (p vgdoc) stroke(s render.Style) {
+	openvg.StrokeWidth(openvg.VGfloat(s.Width))
+	c, alpha := render.Color(s.Color, s.Opacity)
+	openvg.StrokeRGB(c.R, c.G, c.B, openvg.VGfloat(alpha))
+	openvg.FillRGB(c.R, c.G, c.B, 0)
+}
+
+// Arc draws an arc
+func (p vgdoc) Arc(x, y, w, h, a1, a2 float64, s render.Style) {
+	if len(s.Dash) > 0 {
+		px, py := render.ArcPoints(x, y, w, h, a1, a2)
+		p.dashes(px, py, s)
+		return
 	}
-	// curve
-	for _, curve := range slide.Curve {
-		if curve.Color == "" {
-			curve.Color = defaultColor
-		}
-		if curve.Opacity == 0 {
-			strokeopacity = 1
-		} else {
-			strokeopacity = curve.Opacity / 100.0
-		}
-		x1, y1, sw := dimen(d, curve.Xp1, curve.Yp1, curve.Sp)
-		x2, y2, _ := dimen(d, curve.Xp2, curve.Yp2, 0)
-		x3, y3, _ := dimen(d, curve.Xp3, curve.Yp3, 0)
-		openvg.StrokeColor(curve.Color, openvg.VGfloat(strokeopacity))
-		openvg.FillColor(slide.Bg, openvg.VGfloat(curve.Opacity))
-		if sw == 0 {
-			sw = defaultSw
-		}
-		openvg.StrokeWidth(sw)
-		openvg.Qbezier(x1, y1, x2, y2, x3, y3)
-		openvg.StrokeWidth(0)
+	p.stroke(s)
+	openvg.Arc(openvg.VGfloat(x), p.ch-openvg.VGfloat(y), openvg.VGfloat(w*2), openvg.VGfloat(h*2), openvg.VGfloat(a1), openvg.VGfloat(a2))
+	openvg.StrokeWidth(0)
+}
+
+// Curve draws a quadratic bezier curve
+func (p vgdoc) Curve(x1, y1, x2, y2, x3, y3 float64, s render.Style) {
+	if len(s.Dash) > 0 {
+		px, py := render.CurvePoints(x1, y1, x2, y2, x3, y3)
+		p.dashes(px, py, s)
+		return
 	}
+	p.stroke(s)
+	openvg.Qbezier(openvg.VGfloat(x1), p.ch-openvg.VGfloat(y1), openvg.VGfloat(x2), p.ch-openvg.VGfloat(y2), openvg.VGfloat(x3), p.ch-openvg.VGfloat(y3))
+	openvg.StrokeWidth(0)
+}
 
-	// arc
-	for _, arc := range slide.Arc {
-		if arc.Color == "" {
-			arc.Color = defaultColor
-		}
-		if arc.Opacity == 0 {
-			strokeopacity = 1
-		} else {
-			strokeopacity = arc.Opacity / 100.0
-		}
-		ax, ay, sw := dimen(d, arc.Xp, arc.Yp, arc.Sp)
-		w := pct(arc.Wp, cw)
-		h := pct(arc.Hp, cw)
-		openvg.StrokeColor(arc.Color, openvg.VGfloat(strokeopacity))
-		openvg.FillColor(slide.Bg, openvg.VGfloat(arc.Opacity))
-		if sw == 0 {
-			sw = defaultSw
-		}
-		openvg.StrokeWidth(sw)
-		openvg.Arc(ax, ay, w, h, openvg.VGfloat(arc.A1), openvg.VGfloat(arc.A2))
-		openvg.StrokeWidth(0)
+// Line draws a line
+func (p vgdoc) Line(x1, y1, x2, y2 float64, s render.Style) {
+	if len(s.Dash) > 0 {
+		p.dashes([]float64{x1, x2}, []float64{y1, y2}, s)
+		return
 	}
+	p.stroke(s)
+	openvg.Line(openvg.VGfloat(x1), p.ch-openvg.VGfloat(y1), openvg.VGfloat(x2), p.ch-openvg.VGfloat(y2))
+	openvg.StrokeWidth(0)
+}
 
-	// polygon
-	for _, poly := range slide.Polygon {
-		if poly.Color == "" {
-			poly.Color = defaultColor
-		}
-		if poly.Opacity == 0 {
-			poly.Opacity = 1
-		} else {
-			poly.Opacity /= 100
-		}
-		xs := strings.Split(poly.XC, " ")
-		ys := strings.Split(poly.YC, " ")
-		if len(xs) != len(ys) {
-			continue
-		}
-		if len(xs) < 3 || len(ys) < 3 {
-			continue
-		}
-		px := make([]openvg.VGfloat, len(xs))
-		py := make([]openvg.VGfloat, len(ys))
-		for i := 0; i < len(xs); i++ {
-			x, err := strconv.ParseFloat(xs[i], 32)
-			if err != nil {
-				px[i] = 0
-			} else {
-				px[i] = pct(x, cw)
-			}
-			y, err := strconv.ParseFloat(ys[i], 32)
-			if err != nil {
-				py[i] = 0
-			} else {
-				py[i] = pct(y, ch)
-			}
-		}
-		openvg.FillColor(poly.Color, openvg.VGfloat(poly.Opacity))
+// vgcoords converts canvas coordinates
+func (p vgdoc) vgcoords(x, y []float64) ([]openvg.VGfloat, []openvg.VGfloat) {
+	px := make([]openvg.VGfloat, len(x))
+	py := make([]openvg.VGfloat, len(y))
+	for i := range x {
+		px[i] = openvg.VGfloat(x[i])
+		py[i] = p.ch - openvg.VGfloat(y[i])
+	}
+	return px, py
+}
+
+// Polygon draws a polygon
+func (p vgdoc) Polygon(x, y []float64, s render.Style) {
+	if !s.NoFill {
+		px, py := p.vgcoords(x, y)
+		p.fill(s)
 		openvg.Polygon(px, py)
 	}
+	p.outline(append(x[:len(x):len(x)], x[0]), append(y[:len(y):len(y)], y[0]), s)
+}
 
-	openvg.FillColor(slide.Fg)
+// Polyline draws connected line segments
+func (p vgdoc) Polyline(x, y []float64, s render.Style) {
+	p.dashes(x, y, s)
+}
 
-	// every list in the slide
-	var offset, textopacity openvg.VGfloat
-	const blinespacing = 2.4
-	for _, l := range slide.List {
-		if l.Font == "" {
-			l.Font = "sans"
-		}
-		x, y, fs = dimen(d, l.Xp, l.Yp, l.Sp)
-		if l.Type == "bullet" {
-			offset = 1.2 * fs
-		} else {
-			offset = 0
-		}
-		if l.Lp == 0 {
-			l.Lp = blinespacing
-		}
-		if l.Opacity == 0 {
-			textopacity = 1
-		} else {
-			textopacity = openvg.VGfloat(l.Opacity / 100)
-		}
-		// every list item
-		var li, lifont string
-		for ln, tl := range l.Li {
-			if len(l.Color) > 0 {
-				openvg.FillColor(l.Color, textopacity)
-			} else {
-				openvg.FillColor(slide.Fg)
-			}
-			if l.Type == "bullet" {
-				boffset := fs / 2
-				openvg.Ellipse(x, y+boffset, boffset, boffset)
-				//openvg.Rect(x, y+boffset/2, boffset, boffset)
-			}
-			if l.Type == "number" {
-				li = fmt.Sprintf("%d. ", ln+1) + tl.ListText
-			} else {
-				li = tl.ListText
-			}
-			if len(tl.Color) > 0 {
-				openvg.FillColor(tl.Color, textopacity)
-			}
-			if len(tl.Font) > 0 {
-				lifont = tl.Font
-			} else {
-				lifont = l.Font
-			}
-			showtext(x+offset, y, li, l.Align, lifont, fs)
-			y -= fs * openvg.VGfloat(l.Lp)
+// Path approximates the subpaths of a path with polygons
+func (p vgdoc) Path(ops []render.PathOp, s render.Style) {
+	px, py := render.Subpaths(ops)
+	for i := range px {
+		if !s.NoFill {
+			x, y := p.vgcoords(px[i], py[i])
+			p.fill(s)
+			openvg.Polygon(x, y)
 		}
+		p.outline(px[i], py[i], s)
 	}
-	openvg.FillColor(slide.Fg)
+}
 
-	// every text in the slide
-	const linespacing = 1.8
+// Text displays text
+func (p vgdoc) Text(x, y float64, t string, s render.Style) {
+	p.fill(s)
+	vx, vy := openvg.VGfloat(x), p.ch-openvg.VGfloat(y)
+	switch s.Align {
+	case "center", "middle", "mid", "c":
+		openvg.TextMid(vx, vy, t, s.Font, textsize(s))
+	case "right", "end", "e":
+		openvg.TextEnd(vx, vy, t, s.Font, textsize(s))
+	default:
+		openvg.Text(vx, vy, t, s.Font, textsize(s))
+	}
+}
 
-	var tdata string
-	for _, t := range slide.Text {
-		if t.File != "" {
-			tdata = includefile(t.File)
-		} else {
-			tdata = t.Tdata
-		}
-		if t.Font == "" {
-			t.Font = "sans"
-		}
-		if t.Opacity == 0 {
-			textopacity = 1
-		} else {
-			textopacity = openvg.VGfloat(t.Opacity / 100)
-		}
-		if t.Lp == 0 {
-			t.Lp = linespacing
-		}
-		x, y, fs = dimen(d, t.Xp, t.Yp, t.Sp)
-		td := strings.Split(tdata, "\n")
-		if t.Type == "code" {
-			ls := fs * openvg.VGfloat(t.Lp)
-			t.Font = "mono"
-			tdepth := (ls * openvg.VGfloat(len(td))) + fs
-			openvg.FillColor("rgb(240,240,240)")
-			openvg.Rect(x-20, y-tdepth+(ls), pctwidth(t.Wp, cw, cw-x-20), tdepth)
-		}
-		if t.Color == "" {
-			openvg.FillColor(slide.Fg, textopacity)
-		} else {
-			openvg.FillColor(t.Color, textopacity)
-		}
-		if t.Type == "block" {
-			textwrap(x, y, pctwidth(t.Wp, cw, cw/2), tdata, t.Font, fs, fs*openvg.VGfloat(t.Lp), 0.3)
-		} else {
-			// every text line
-			ls := fs * openvg.VGfloat(t.Lp)
-			for _, txt := range td {
-				showtext(x, y, txt, t.Align, t.Font, fs)
-				y -= ls
-			}
-		}
+// TextWidth returns the width of text
+func (p vgdoc) TextWidth(t string, s render.Style) float64 {
+	return float64(openvg.TextWidth(t, s.Font, textsize(s)))
+}
+
+// Image places a preloaded image centered at (x,y)
+func (p vgdoc) Image(x, y, w, h float64, name string, s render.Style) {
+	img, ok := p.imap[name]
+	if !ok {
+		return
 	}
-	openvg.FillColor(slide.Fg)
-	openvg.End()
+	bounds := img.Bounds()
+	midx := openvg.VGfloat(bounds.Dx() / 2)
+	midy := openvg.VGfloat(bounds.Dy() / 2)
+	openvg.Img(openvg.VGfloat(x)-midx, p.ch-openvg.VGfloat(y)-midy, img)
 }
 
-// whitespace determines if a rune is whitespace
-func whitespace(r rune) bool {
-	return r == ' ' || r == '\n' || r == '\t' || r == '-'
+// Rotate begins a rotation about (x,y)
+func (p vgdoc) Rotate(x, y, angle float64) {
+	vx, vy := openvg.VGfloat(x), p.ch-openvg.VGfloat(y)
+	openvg.SaveMatrix()
+	openvg.Translate(vx, vy)
+	openvg.Rotate(openvg.VGfloat(angle))
+	openvg.Translate(-vx, -vy)
 }
 
-// textwrap draws text at location, wrapping at the specified width
-func textwrap(x, y, w openvg.VGfloat, s string, font string, fs, leading, factor openvg.VGfloat) {
-	size := int(fs)
-	if font == "mono" {
-		factor = 1.0
-	}
-	wordspacing := openvg.TextWidth("m", font, size)
-	words := strings.FieldsFunc(s, whitespace)
-	xp := x
-	yp := y
-	edge := x + w
-	for _, s := range words {
-		tw := openvg.TextWidth(s, font, size)
-		openvg.Text(xp, yp, s, font, size)
-		xp += tw + (wordspacing * factor)
-		if xp > edge {
-			xp = x
-			yp -= leading
-		}
+// EndRotate ends a rotation
+func (p vgdoc) EndRotate() {
+	openvg.RestoreMatrix()
+}
+
+// showlide displays slides
+func showslide(d deck.Deck, imap map[string]image.Image, n, step int) {
+	if n < 0 || n > len(d.Slide)-1 {
+		return
 	}
+	openvg.Start(d.Canvas.Width, d.Canvas.Height)
+	render.Slide(vgdoc{imap, openvg.VGfloat(d.Canvas.Height)}, d, n, render.Options{Step: step})
+	openvg.End()
 }
 
-// includefile returns the contents of a file as string
-func includefile(filename string) string {
-	data, err := ioutil.ReadFile(filename)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "%v\n", err)
-		return ""
+// steps returns the number of build steps of slide n
+func steps(d deck.Deck, n int) int {
+	if n < 0 || n > len(d.Slide)-1 {
+		return 1
 	}
-	return codemap.Replace(string(data))
+	return deck.Steps(d.Slide[n])
 }
 
 // readcmd reads interaction commands
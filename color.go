@@ -0,0 +1,360 @@
+package deck
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// colornames maps SVG color names to RGB triples.
+var colornames = map[string]color.RGBA{
+	"aliceblue":            {240, 248, 255, 255},
+	"antiquewhite":         {250, 235, 215, 255},
+	"aqua":                 {0, 255, 255, 255},
+	"aquamarine":           {127, 255, 212, 255},
+	"azure":                {240, 255, 255, 255},
+	"beige":                {245, 245, 220, 255},
+	"bisque":               {255, 228, 196, 255},
+	"black":                {0, 0, 0, 255},
+	"blanchedalmond":       {255, 235, 205, 255},
+	"blue":                 {0, 0, 255, 255},
+	"blueviolet":           {138, 43, 226, 255},
+	"brown":                {165, 42, 42, 255},
+	"burlywood":            {222, 184, 135, 255},
+	"cadetblue":            {95, 158, 160, 255},
+	"chartreuse":           {127, 255, 0, 255},
+	"chocolate":            {210, 105, 30, 255},
+	"coral":                {255, 127, 80, 255},
+	"cornflowerblue":       {100, 149, 237, 255},
+	"cornsilk":             {255, 248, 220, 255},
+	"crimson":              {220, 20, 60, 255},
+	"cyan":                 {0, 255, 255, 255},
+	"darkblue":             {0, 0, 139, 255},
+	"darkcyan":             {0, 139, 139, 255},
+	"darkgoldenrod":        {184, 134, 11, 255},
+	"darkgray":             {169, 169, 169, 255},
+	"darkgreen":            {0, 100, 0, 255},
+	"darkgrey":             {169, 169, 169, 255},
+	"darkkhaki":            {189, 183, 107, 255},
+	"darkmagenta":          {139, 0, 139, 255},
+	"darkolivegreen":       {85, 107, 47, 255},
+	"darkorange":           {255, 140, 0, 255},
+	"darkorchid":           {153, 50, 204, 255},
+	"darkred":              {139, 0, 0, 255},
+	"darksalmon":           {233, 150, 122, 255},
+	"darkseagreen":         {143, 188, 143, 255},
+	"darkslateblue":        {72, 61, 139, 255},
+	"darkslategray":        {47, 79, 79, 255},
+	"darkslategrey":        {47, 79, 79, 255},
+	"darkturquoise":        {0, 206, 209, 255},
+	"darkviolet":           {148, 0, 211, 255},
+	"deeppink":             {255, 20, 147, 255},
+	"deepskyblue":          {0, 191, 255, 255},
+	"dimgray":              {105, 105, 105, 255},
+	"dimgrey":              {105, 105, 105, 255},
+	"dodgerblue":           {30, 144, 255, 255},
+	"firebrick":            {178, 34, 34, 255},
+	"floralwhite":          {255, 250, 240, 255},
+	"forestgreen":          {34, 139, 34, 255},
+	"fuchsia":              {255, 0, 255, 255},
+	"gainsboro":            {220, 220, 220, 255},
+	"ghostwhite":           {248, 248, 255, 255},
+	"gold":                 {255, 215, 0, 255},
+	"goldenrod":            {218, 165, 32, 255},
+	"gray":                 {128, 128, 128, 255},
+	"green":                {0, 128, 0, 255},
+	"greenyellow":          {173, 255, 47, 255},
+	"grey":                 {128, 128, 128, 255},
+	"honeydew":             {240, 255, 240, 255},
+	"hotpink":              {255, 105, 180, 255},
+	"indianred":            {205, 92, 92, 255},
+	"indigo":               {75, 0, 130, 255},
+	"ivory":                {255, 255, 240, 255},
+	"khaki":                {240, 230, 140, 255},
+	"lavender":             {230, 230, 250, 255},
+	"lavenderblush":        {255, 240, 245, 255},
+	"lawngreen":            {124, 252, 0, 255},
+	"lemonchiffon":         {255, 250, 205, 255},
+	"lightblue":            {173, 216, 230, 255},
+	"lightcoral":           {240, 128, 128, 255},
+	"lightcyan":            {224, 255, 255, 255},
+	"lightgoldenrodyellow": {250, 250, 210, 255},
+	"lightgray":            {211, 211, 211, 255},
+	"lightgreen":           {144, 238, 144, 255},
+	"lightgrey":            {211, 211, 211, 255},
+	"lightpink":            {255, 182, 193, 255},
+	"lightsalmon":          {255, 160, 122, 255},
+	"lightseagreen":        {32, 178, 170, 255},
+	"lightskyblue":         {135, 206, 250, 255},
+	"lightslategray":       {119, 136, 153, 255},
+	"lightslategrey":       {119, 136, 153, 255},
+	"lightsteelblue":       {176, 196, 222, 255},
+	"lightyellow":          {255, 255, 224, 255},
+	"lime":                 {0, 255, 0, 255},
+	"limegreen":            {50, 205, 50, 255},
+	"linen":                {250, 240, 230, 255},
+	"magenta":              {255, 0, 255, 255},
+	"maroon":               {128, 0, 0, 255},
+	"mediumaquamarine":     {102, 205, 170, 255},
+	"mediumblue":           {0, 0, 205, 255},
+	"mediumorchid":         {186, 85, 211, 255},
+	"mediumpurple":         {147, 112, 219, 255},
+	"mediumseagreen":       {60, 179, 113, 255},
+	"mediumslateblue":      {123, 104, 238, 255},
+	"mediumspringgreen":    {0, 250, 154, 255},
+	"mediumturquoise":      {72, 209, 204, 255},
+	"mediumvioletred":      {199, 21, 133, 255},
+	"midnightblue":         {25, 25, 112, 255},
+	"mintcream":            {245, 255, 250, 255},
+	"mistyrose":            {255, 228, 225, 255},
+	"moccasin":             {255, 228, 181, 255},
+	"navajowhite":          {255, 222, 173, 255},
+	"navy":                 {0, 0, 128, 255},
+	"oldlace":              {253, 245, 230, 255},
+	"olive":                {128, 128, 0, 255},
+	"olivedrab":            {107, 142, 35, 255},
+	"orange":               {255, 165, 0, 255},
+	"orangered":            {255, 69, 0, 255},
+	"orchid":               {218, 112, 214, 255},
+	"palegoldenrod":        {238, 232, 170, 255},
+	"palegreen":            {152, 251, 152, 255},
+	"paleturquoise":        {175, 238, 238, 255},
+	"palevioletred":        {219, 112, 147, 255},
+	"papayawhip":           {255, 239, 213, 255},
+	"peachpuff":            {255, 218, 185, 255},
+	"peru":                 {205, 133, 63, 255},
+	"pink":                 {255, 192, 203, 255},
+	"plum":                 {221, 160, 221, 255},
+	"powderblue":           {176, 224, 230, 255},
+	"purple":               {128, 0, 128, 255},
+	"red":                  {255, 0, 0, 255},
+	"rosybrown":            {188, 143, 143, 255},
+	"royalblue":            {65, 105, 225, 255},
+	"saddlebrown":          {139, 69, 19, 255},
+	"salmon":               {250, 128, 114, 255},
+	"sandybrown":           {244, 164, 96, 255},
+	"seagreen":             {46, 139, 87, 255},
+	"seashell":             {255, 245, 238, 255},
+	"sienna":               {160, 82, 45, 255},
+	"silver":               {192, 192, 192, 255},
+	"skyblue":              {135, 206, 235, 255},
+	"slateblue":            {106, 90, 205, 255},
+	"slategray":            {112, 128, 144, 255},
+	"slategrey":            {112, 128, 144, 255},
+	"snow":                 {255, 250, 250, 255},
+	"springgreen":          {0, 255, 127, 255},
+	"steelblue":            {70, 130, 180, 255},
+	"tan":                  {210, 180, 140, 255},
+	"teal":                 {0, 128, 128, 255},
+	"thistle":              {216, 191, 216, 255},
+	"tomato":               {255, 99, 71, 255},
+	"turquoise":            {64, 224, 208, 255},
+	"violet":               {238, 130, 238, 255},
+	"wheat":                {245, 222, 179, 255},
+	"white":                {255, 255, 255, 255},
+	"whitesmoke":           {245, 245, 245, 255},
+	"yellow":               {255, 255, 0, 255},
+	"yellowgreen":          {154, 205, 50, 255},
+}
+
+// ParseColor returns the color described by s, which may be a SVG color name,
+// "#rgb", "#rrggbb", "#rrggbbaa", "rgb(r,g,b)", "rgba(r,g,b,a)",
+// "hsv(hue,sat,value)" or "hsl(hue,sat,lightness)".
+// rgb components range from 0-255, rgba alpha from 0-1,
+// hue from 0-360, and saturation, value and lightness from 0-100.
+// The red, green and blue components are not alpha-premultiplied.
+// On error, opaque black is returned along with the error.
+func ParseColor(s string) (color.RGBA, error) {
+	black := color.RGBA{0, 0, 0, 255}
+	s = strings.TrimSpace(s)
+	if c, ok := colornames[strings.ToLower(s)]; ok {
+		return c, nil
+	}
+	if strings.HasPrefix(s, "#") {
+		c, err := hexcolor(s[1:])
+		if err != nil {
+			return black, fmt.Errorf("bad color %q: %v", s, err)
+		}
+		return c, nil
+	}
+	fn, args, ok := colorfunc(s)
+	if !ok {
+		return black, fmt.Errorf("unknown color %q", s)
+	}
+	var c color.RGBA
+	var err error
+	switch fn {
+	case "rgb", "rgba":
+		c, err = rgbcolor(fn, args)
+	case "hsv", "hsl":
+		c, err = hcolor(fn, args)
+	default:
+		err = fmt.Errorf("unknown color function %s", fn)
+	}
+	if err != nil {
+		return black, fmt.Errorf("bad color %q: %v", s, err)
+	}
+	return c, nil
+}
+
+// ColorList splits a space-separated list of colors, such as "red rgb(0, 128, 0) #00f",
+// keeping the spaces within the arguments of color functions
+func ColorList(s string) []string {
+	var colors []string
+	depth, start := 0, -1
+	for i, r := range s {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+		case depth == 0 && (r == ' ' || r == '\t' || r == '\n' || r == '\r'):
+			if start >= 0 {
+				colors = append(colors, s[start:i])
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		colors = append(colors, s[start:])
+	}
+	return colors
+}
+
+// hexcolor parses rgb, rrggbb and rrggbbaa hex strings
+func hexcolor(s string) (color.RGBA, error) {
+	var v []uint8
+	switch len(s) {
+	case 3:
+		for i := 0; i < 3; i++ {
+			n, err := strconv.ParseUint(s[i:i+1], 16, 8)
+			if err != nil {
+				return color.RGBA{}, err
+			}
+			v = append(v, uint8(n*17))
+		}
+		v = append(v, 255)
+	case 6, 8:
+		for i := 0; i < len(s); i += 2 {
+			n, err := strconv.ParseUint(s[i:i+2], 16, 8)
+			if err != nil {
+				return color.RGBA{}, err
+			}
+			v = append(v, uint8(n))
+		}
+		if len(v) == 3 {
+			v = append(v, 255)
+		}
+	default:
+		return color.RGBA{}, fmt.Errorf("want 3, 6 or 8 hex digits")
+	}
+	return color.RGBA{v[0], v[1], v[2], v[3]}, nil
+}
+
+// colorfunc splits a string of the form name(n1, n2, ...) into
+// the function name and its numeric arguments.
+func colorfunc(s string) (string, []float64, bool) {
+	open := strings.Index(s, "(")
+	if open < 1 || !strings.HasSuffix(s, ")") {
+		return "", nil, false
+	}
+	fn := strings.ToLower(strings.TrimSpace(s[:open]))
+	fields := strings.Split(s[open+1:len(s)-1], ",")
+	args := make([]float64, len(fields))
+	for i, f := range fields {
+		f = strings.TrimSuffix(strings.TrimSpace(f), "%")
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return "", nil, false
+		}
+		args[i] = v
+	}
+	return fn, args, true
+}
+
+// rgbcolor makes a color from rgb(r,g,b) or rgba(r,g,b,a) arguments
+func rgbcolor(fn string, v []float64) (color.RGBA, error) {
+	n := 3
+	if fn == "rgba" {
+		n = 4
+	}
+	if len(v) != n {
+		return color.RGBA{}, fmt.Errorf("%s needs %d values", fn, n)
+	}
+	for _, c := range v[:3] {
+		if c < 0 || c > 255 {
+			return color.RGBA{}, fmt.Errorf("component %v out of range 0-255", c)
+		}
+	}
+	alpha := 1.0
+	if n == 4 {
+		alpha = v[3]
+		if alpha < 0 || alpha > 1 {
+			return color.RGBA{}, fmt.Errorf("alpha %v out of range 0-1", alpha)
+		}
+	}
+	return color.RGBA{uint8(v[0]), uint8(v[1]), uint8(v[2]), uint8(math.Round(alpha * 255))}, nil
+}
+
+// hcolor makes a color from hsv(h,s,v) or hsl(h,s,l) arguments
+func hcolor(fn string, v []float64) (color.RGBA, error) {
+	if len(v) != 3 {
+		return color.RGBA{}, fmt.Errorf("%s needs 3 values", fn)
+	}
+	if v[1] < 0 || v[1] > 100 || v[2] < 0 || v[2] > 100 {
+		return color.RGBA{}, fmt.Errorf("saturation and %c must range from 0-100", fn[2])
+	}
+	h, s, x := math.Mod(v[0], 360), v[1]/100, v[2]/100
+	if h < 0 {
+		h += 360
+	}
+	var r, g, b float64
+	if fn == "hsv" {
+		r, g, b = hsv2rgb(h, s, x)
+	} else {
+		r, g, b = hsl2rgb(h, s, x)
+	}
+	return color.RGBA{uint8(math.Round(r * 255)), uint8(math.Round(g * 255)), uint8(math.Round(b * 255)), 255}, nil
+}
+
+// hue2rgb returns the rgb triple (0-1) for a hue (0-360) at the specified
+// chroma, adding m to each component
+// reference: https://en.wikipedia.org/wiki/HSL_and_HSV#To_RGB
+func hue2rgb(h, c, m float64) (float64, float64, float64) {
+	section := h / 60
+	x := c * (1 - math.Abs(math.Mod(section, 2)-1))
+	var r, g, b float64
+	switch {
+	case section < 1:
+		r, g, b = c, x, 0
+	case section < 2:
+		r, g, b = x, c, 0
+	case section < 3:
+		r, g, b = 0, c, x
+	case section < 4:
+		r, g, b = 0, x, c
+	case section < 5:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	return r + m, g + m, b + m
+}
+
+// hsv2rgb converts hue (0-360), saturation (0-1) and value (0-1) to rgb (0-1)
+func hsv2rgb(h, s, v float64) (float64, float64, float64) {
+	c := v * s
+	return hue2rgb(h, c, v-c)
+}
+
+// hsl2rgb converts hue (0-360), saturation (0-1) and lightness (0-1) to rgb (0-1)
+func hsl2rgb(h, s, l float64) (float64, float64, float64) {
+	c := (1 - math.Abs(2*l-1)) * s
+	return hue2rgb(h, c, l-c/2)
+}
@@ -0,0 +1,62 @@
+package deck
+
+import (
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		in  string
+		out color.RGBA
+	}{
+		{"red", color.RGBA{255, 0, 0, 255}},
+		{"SteelBlue", color.RGBA{70, 130, 180, 255}},
+		{"#f80", color.RGBA{255, 136, 0, 255}},
+		{"#336699", color.RGBA{51, 102, 153, 255}},
+		{"#33669980", color.RGBA{51, 102, 153, 128}},
+		{"rgb(10, 20, 30)", color.RGBA{10, 20, 30, 255}},
+		{"rgba(10,20,30,0.5)", color.RGBA{10, 20, 30, 128}},
+		{"hsv(120,100,100)", color.RGBA{0, 255, 0, 255}},
+		{"hsv(0,0,50)", color.RGBA{128, 128, 128, 255}},
+		{"hsl(240,100%,50%)", color.RGBA{0, 0, 255, 255}},
+		{"hsl(0,0,100)", color.RGBA{255, 255, 255, 255}},
+	}
+	for _, tc := range tests {
+		c, err := ParseColor(tc.in)
+		if err != nil {
+			t.Errorf("ParseColor(%q): %v", tc.in, err)
+			continue
+		}
+		if c != tc.out {
+			t.Errorf("ParseColor(%q) = %v, want %v", tc.in, c, tc.out)
+		}
+	}
+	for _, s := range []string{"", "gren", "#12", "#ggg", "rgb(1,2)", "rgb(300,0,0)", "rgba(1,2,3,2)", "hsv(0,200,0)", "cmyk(0,0,0,0)"} {
+		c, err := ParseColor(s)
+		if err == nil {
+			t.Errorf("ParseColor(%q): expected an error", s)
+		}
+		if c != (color.RGBA{0, 0, 0, 255}) {
+			t.Errorf("ParseColor(%q) = %v, want black on error", s, c)
+		}
+	}
+}
+
+func TestColorList(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"red  blue", []string{"red", "blue"}},
+		{" rgb(10, 20, 30) hsl(120,50, 50)\t#00f ", []string{"rgb(10, 20, 30)", "hsl(120,50, 50)", "#00f"}},
+		{"rgba( 1 ,2, 3, 0.5 )", []string{"rgba( 1 ,2, 3, 0.5 )"}},
+	}
+	for _, test := range tests {
+		if got := ColorList(test.in); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ColorList(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
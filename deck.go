@@ -5,76 +5,131 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
-	"os"
 	"strings"
 )
 
 // Deck defines the structure of a presentation deck
 // The size of the canvas, and series of slides
 type Deck struct {
-	Title       string  `xml:"title"`
-	Creator     string  `xml:"creator"`
-	Subject     string  `xml:"subject"`
-	Publisher   string  `xml:"publisher"`
-	Description string  `xml:"description"`
-	Date        string  `xml:"date"`
-	Canvas      canvas  `xml:"canvas"`
-	Slide       []Slide `xml:"slide"`
+	Title       string     `xml:"title" json:"title,omitempty"`
+	Creator     string     `xml:"creator" json:"creator,omitempty"`
+	Subject     string     `xml:"subject" json:"subject,omitempty"`
+	Publisher   string     `xml:"publisher" json:"publisher,omitempty"`
+	Description string     `xml:"description" json:"description,omitempty"`
+	Date        string     `xml:"date" json:"date,omitempty"`
+	Canvas      canvas     `xml:"canvas" json:"canvas,omitempty"`
+	Template    []Template `xml:"template" json:"template,omitempty"`
+	Slide       []Slide    `xml:"slide" json:"slide,omitempty"`
 }
 
 type canvas struct {
-	Width  int `xml:"width,attr"`
-	Height int `xml:"height,attr"`
+	Width  int `xml:"width,attr" json:"width,omitempty"`
+	Height int `xml:"height,attr" json:"height,omitempty"`
 }
 
 // Slide is the structure of an individual slide within a deck
 // <slide bg="black" fg="rgb(255,255,255)" duration="2s" note="hello, world">
 // <slide gradcolor1="black" gradcolor2="white" gp="20" duration="2s" note="wassup">
+// Slides with elements at build steps (see Steps) are shown once per step,
+// with the elements of earlier steps dimmed to the dim opacity, if specified:
+// <slide dim="40">
+// Viewers may advance slides after their duration, with a transition:
+// <slide duration="10s" transition="dissolve">
 type Slide struct {
-	Bg          string     `xml:"bg,attr"`
-	Fg          string     `xml:"fg,attr"`
-	Gradcolor1  string     `xml:"gradcolor1,attr"`
-	Gradcolor2  string     `xml:"gradcolor2,attr"`
-	GradPercent float64    `xml:"gp,attr"`
-	Duration    string     `xml:"duration,attr"`
-	Note        string     `xml:"note"`
-	List        []List     `xml:"list"`
-	Text        []Text     `xml:"text"`
-	Image       []Image    `xml:"image"`
-	Ellipse     []Ellipse  `xml:"ellipse"`
-	Line        []Line     `xml:"line"`
-	Rect        []Rect     `xml:"rect"`
-	Curve       []Curve    `xml:"curve"`
-	Arc         []Arc      `xml:"arc"`
-	Polygon     []Polygon  `xml:"polygon"`
-	Polyline    []Polyline `xml:"polyline"`
+	Template string `xml:"template,attr" json:"template,omitempty"`
+	Bg       string `xml:"bg,attr" json:"bg,omitempty"`
+	Fg       string `xml:"fg,attr" json:"fg,omitempty"`
+	Gradient
+	Duration   string      `xml:"duration,attr" json:"duration,omitempty"`
+	Transition string      `xml:"transition,attr" json:"transition,omitempty"` // dissolve, wipe, fade, push, cover, uncover, split, blinds, box, glitter
+	Dim        float64     `xml:"dim,attr" json:"dim,omitempty"`               // opacity of elements from earlier build steps
+	Note       string      `xml:"note" json:"note,omitempty"`
+	List       []List      `xml:"list" json:"list,omitempty"`
+	Text       []Text      `xml:"text" json:"text,omitempty"`
+	Image      []Image     `xml:"image" json:"image,omitempty"`
+	Ellipse    []Ellipse   `xml:"ellipse" json:"ellipse,omitempty"`
+	Line       []Line      `xml:"line" json:"line,omitempty"`
+	Rect       []Rect      `xml:"rect" json:"rect,omitempty"`
+	Curve      []Curve     `xml:"curve" json:"curve,omitempty"`
+	Arc        []Arc       `xml:"arc" json:"arc,omitempty"`
+	Polygon    []Polygon   `xml:"polygon" json:"polygon,omitempty"`
+	Polyline   []Polyline  `xml:"polyline" json:"polyline,omitempty"`
+	Path       []Path      `xml:"path" json:"path,omitempty"`
+	Connector  []Connector `xml:"connector" json:"connector,omitempty"`
+	Table      []Table     `xml:"table" json:"table,omitempty"`
+	Chart      []Chart     `xml:"chart" json:"chart,omitempty"`
+	Group      []Group     `xml:"group" json:"group,omitempty"`
 }
 
 // CommonAttr are the common attributes for text and list
 type CommonAttr struct {
-	Xp          float64 `xml:"xp,attr"`         // X coordinate
-	Yp          float64 `xml:"yp,attr"`         // Y coordinate
-	Sp          float64 `xml:"sp,attr"`         // size
-	Lp          float64 `xml:"lp,attr"`         // linespacing (leading) percentage
-	Rotation    float64 `xml:"rotation,attr"`   // Rotation (0-360 degrees)
-	Type        string  `xml:"type,attr"`       // type: block, plain, code, number, bullet
-	Align       string  `xml:"align,attr"`      // alignment: center, end, begin
-	Color       string  `xml:"color,attr"`      // item color
-	Gradcolor1  string  `xml:"gradcolor1,attr"` // gradient color 1
-	Gradcolor2  string  `xml:"gradcolor2,attr"` // gradient color 2
-	GradPercent float64 `xml:"gp,attr"`         // gradient percentage
-	Opacity     float64 `xml:"opacity,attr"`    // opacity percentage
-	Font        string  `xml:"font,attr"`       // font type: i.e. sans, serif, mono
-	Link        string  `xml:"link,attr"`       // reference to other content (i.e. http:// or mailto:)
+	ID       string  `xml:"id,attr" json:"id,omitempty"`             // identifier, for connectors
+	Xp       float64 `xml:"xp,attr" json:"xp,omitempty"`             // X coordinate
+	Yp       float64 `xml:"yp,attr" json:"yp,omitempty"`             // Y coordinate
+	Sp       float64 `xml:"sp,attr" json:"sp,omitempty"`             // size
+	Lp       float64 `xml:"lp,attr" json:"lp,omitempty"`             // linespacing (leading) percentage
+	Rotation float64 `xml:"rotation,attr" json:"rotation,omitempty"` // Rotation (0-360 degrees)
+	Type     string  `xml:"type,attr" json:"type,omitempty"`         // type: block, plain, code, number, bullet
+	Align    string  `xml:"align,attr" json:"align,omitempty"`       // alignment: center, end, begin
+	Color    string  `xml:"color,attr" json:"color,omitempty"`       // item color
+	Gradient
+	Opacity float64 `xml:"opacity,attr" json:"opacity,omitempty"` // opacity percentage
+	Font    string  `xml:"font,attr" json:"font,omitempty"`       // font type: i.e. sans, serif, mono
+	Link    string  `xml:"link,attr" json:"link,omitempty"`       // reference to other content (i.e. http:// or mailto:)
+	Step    int     `xml:"step,attr" json:"step,omitempty"`       // build step at which the element appears
+}
+
+// Gradient describes a gradient fill, used when both colors are specified.
+// Linear gradients run from gradcolor1 at the top to gradcolor2 at the bottom,
+// turned counterclockwise by gradangle degrees (90 runs from left to right);
+// radial gradients (gradtype="radial") run from gradcolor1 at the center to gradcolor2 at the edge.
+// gp is the percentage of the shape covered by the transition, beyond which gradcolor2 continues.
+// <rect xp="50" yp="50" wp="40" hp="20" gradcolor1="white" gradcolor2="steelblue" gradangle="90"/>
+type Gradient struct {
+	Gradcolor1  string  `xml:"gradcolor1,attr" json:"gradcolor1,omitempty"` // gradient color 1
+	Gradcolor2  string  `xml:"gradcolor2,attr" json:"gradcolor2,omitempty"` // gradient color 2
+	GradPercent float64 `xml:"gp,attr" json:"gp,omitempty"`                 // gradient percentage
+	GradAngle   float64 `xml:"gradangle,attr" json:"gradangle,omitempty"`   // direction of linear gradients
+	GradType    string  `xml:"gradtype,attr" json:"gradtype,omitempty"`     // linear or radial
 }
 
 // Dimension describes a graphics object with width and height
 type Dimension struct {
 	CommonAttr
-	Wp float64 `xml:"wp,attr"` // width percentage
-	Hp float64 `xml:"hp,attr"` // height percentage
-	Hr float64 `xml:"hr,attr"` // height relative percentage
-	Hw float64 `xml:"hw,attr"` // height by width
+	Wp float64 `xml:"wp,attr" json:"wp,omitempty"` // width percentage
+	Hp float64 `xml:"hp,attr" json:"hp,omitempty"` // height percentage
+	Hr float64 `xml:"hr,attr" json:"hr,omitempty"` // height relative percentage
+	Hw float64 `xml:"hw,attr" json:"hw,omitempty"` // height by width
+}
+
+// LineStyle describes how lines and outlines are stroked: dash is a pattern of
+// dash and gap lengths, in multiples of the stroke width.
+// <line xp1="20" yp1="10" xp2="80" yp2="10" dash="4 2" linecap="round"/>
+type LineStyle struct {
+	Dash     string `xml:"dash,attr" json:"dash,omitempty"`         // dash pattern
+	Linecap  string `xml:"linecap,attr" json:"linecap,omitempty"`   // line cap: butt, round, square
+	Linejoin string `xml:"linejoin,attr" json:"linejoin,omitempty"` // line join: miter, round, bevel
+}
+
+// Arrowhead describes the arrowheads of a line: at its end, start, or both ends,
+// arrowsize long (a percentage of the canvas width; five times the line width by default),
+// and filled (the default), open or dot:
+// <line xp1="20" yp1="50" xp2="80" yp2="50" arrow="end" arrowstyle="open"/>
+type Arrowhead struct {
+	Arrow      string  `xml:"arrow,attr" json:"arrow,omitempty"`           // end, start, both or none
+	ArrowSize  float64 `xml:"arrowsize,attr" json:"arrowsize,omitempty"`   // length percentage
+	ArrowStyle string  `xml:"arrowstyle,attr" json:"arrowstyle,omitempty"` // filled, open, dot
+}
+
+// Outline describes the outline and fill of a shape. Shapes are filled with their color
+// (or fill, unless it is "none"), and outlined if stroke is specified;
+// unfilled shapes without a stroke are outlined in their color.
+// <rect xp="50" yp="50" wp="20" hp="10" fill="none" stroke="black" strokewidth="0.2"/>
+type Outline struct {
+	Stroke      string  `xml:"stroke,attr" json:"stroke,omitempty"`           // outline color
+	StrokeWidth float64 `xml:"strokewidth,attr" json:"strokewidth,omitempty"` // outline width
+	Fill        string  `xml:"fill,attr" json:"fill,omitempty"`               // fill color, or none
+	LineStyle
 }
 
 // ListItem describes a list item
@@ -85,76 +140,88 @@ type Dimension struct {
 //
 // </list>
 type ListItem struct {
-	Color    string  `xml:"color,attr"`
-	Opacity  float64 `xml:"opacity,attr"`
-	Font     string  `xml:"font,attr"`
-	ListText string  `xml:",chardata"`
+	Color    string  `xml:"color,attr" json:"color,omitempty"`
+	Opacity  float64 `xml:"opacity,attr" json:"opacity,omitempty"`
+	Font     string  `xml:"font,attr" json:"font,omitempty"`
+	ListText string  `xml:",chardata" json:"text,omitempty"` // plain text, including that within inline elements
+	Markup   string  `xml:",innerxml" json:"-"`              // content, including inline elements, while its plain text is unchanged (see TextSpans)
 }
 
-// List describes the list element
+// List describes the list element; with build, its items appear one per build step:
+// <list xp="10" yp="70" build="1">
 type List struct {
 	CommonAttr
-	Wp float64    `xml:"wp,attr"`
-	Li []ListItem `xml:"li"`
+	Wp    float64    `xml:"wp,attr" json:"wp,omitempty"`
+	Build int        `xml:"build,attr" json:"build,omitempty"` // build step at which the first item appears, one item per step
+	Li    []ListItem `xml:"li" json:"li,omitempty"`
 }
 
 // Text describes the text element
 type Text struct {
 	CommonAttr
-	Wp    float64 `xml:"wp,attr"`
-	File  string  `xml:"file,attr"`
-	Tdata string  `xml:",chardata"`
+	Wp     float64 `xml:"wp,attr" json:"wp,omitempty"`
+	File   string  `xml:"file,attr" json:"file,omitempty"`
+	Tdata  string  `xml:",chardata" json:"text,omitempty"` // plain text, including that within inline elements
+	Markup string  `xml:",innerxml" json:"-"`              // content, including inline elements, while its plain text is unchanged (see TextSpans)
 }
 
 // Image describes an image
 // <image xp="20" yp="30" width="256" height="256" scale="50" name="picture.png" caption="Pretty picture"/>
 type Image struct {
 	CommonAttr
-	Width     int     `xml:"width,attr"`     // image width
-	Height    int     `xml:"height,attr"`    // image height
-	Scale     float64 `xml:"scale,attr"`     // image scale percentage
-	Autoscale string  `xml:"autoscale,attr"` // scale the image to the canvas
-	Name      string  `xml:"name,attr"`      // image file name
-	Caption   string  `xml:"caption,attr"`   // image caption
+	Width     int     `xml:"width,attr" json:"width,omitempty"`         // image width
+	Height    int     `xml:"height,attr" json:"height,omitempty"`       // image height
+	Scale     float64 `xml:"scale,attr" json:"scale,omitempty"`         // image scale percentage
+	Autoscale string  `xml:"autoscale,attr" json:"autoscale,omitempty"` // scale the image to the canvas
+	Name      string  `xml:"name,attr" json:"name,omitempty"`           // image file name
+	Caption   string  `xml:"caption,attr" json:"caption,omitempty"`     // image caption
 }
 
 // Ellipse describes a rectangle with x,y,w,h
 // <ellipse xp="45"  yp="10" wp="4" hr="75" color="rgb(0,127,0)"/>
 type Ellipse struct {
 	Dimension
+	Outline
 }
 
 // Rect describes a rectangle with x,y,w,h
 // <rect xp="35"  yp="10" wp="4" hp="3"/>
 type Rect struct {
 	Dimension
+	Outline
 }
 
 // Line defines a straight line
 // <line xp1="20" yp1="10" xp2="30" yp2="10"/>
 type Line struct {
-	Xp1     float64 `xml:"xp1,attr"`     // begin x coordinate
-	Yp1     float64 `xml:"yp1,attr"`     // begin y coordinate
-	Xp2     float64 `xml:"xp2,attr"`     // end x coordinate
-	Yp2     float64 `xml:"yp2,attr"`     // end y coordinate
-	Sp      float64 `xml:"sp,attr"`      // line thickness
-	Color   string  `xml:"color,attr"`   // line color
-	Opacity float64 `xml:"opacity,attr"` // line opacity (1-100)
+	Xp1     float64 `xml:"xp1,attr" json:"xp1,omitempty"`         // begin x coordinate
+	Yp1     float64 `xml:"yp1,attr" json:"yp1,omitempty"`         // begin y coordinate
+	Xp2     float64 `xml:"xp2,attr" json:"xp2,omitempty"`         // end x coordinate
+	Yp2     float64 `xml:"yp2,attr" json:"yp2,omitempty"`         // end y coordinate
+	Sp      float64 `xml:"sp,attr" json:"sp,omitempty"`           // line thickness
+	Color   string  `xml:"color,attr" json:"color,omitempty"`     // line color
+	Opacity float64 `xml:"opacity,attr" json:"opacity,omitempty"` // line opacity (1-100)
+	Step    int     `xml:"step,attr" json:"step,omitempty"`       // build step at which the element appears
+	LineStyle
+	Arrowhead
 }
 
 // Curve defines a quadratic Bezier curve
 // The begining, ending, and control points are required:
 // <curve xp1="60" yp1="10" xp2="75" yp2="20" xp3="70" yp3="10" />
 type Curve struct {
-	Xp1     float64 `xml:"xp1,attr"`
-	Yp1     float64 `xml:"yp1,attr"`
-	Xp2     float64 `xml:"xp2,attr"`
-	Yp2     float64 `xml:"yp2,attr"`
-	Xp3     float64 `xml:"xp3,attr"`
-	Yp3     float64 `xml:"yp3,attr"`
-	Sp      float64 `xml:"sp,attr"`
-	Color   string  `xml:"color,attr"`
-	Opacity float64 `xml:"opacity,attr"`
+	Xp1     float64 `xml:"xp1,attr" json:"xp1,omitempty"`
+	Yp1     float64 `xml:"yp1,attr" json:"yp1,omitempty"`
+	Xp2     float64 `xml:"xp2,attr" json:"xp2,omitempty"`
+	Yp2     float64 `xml:"yp2,attr" json:"yp2,omitempty"`
+	Xp3     float64 `xml:"xp3,attr" json:"xp3,omitempty"`
+	Yp3     float64 `xml:"yp3,attr" json:"yp3,omitempty"`
+	Sp      float64 `xml:"sp,attr" json:"sp,omitempty"`
+	Color   string  `xml:"color,attr" json:"color,omitempty"`
+	Opacity float64 `xml:"opacity,attr" json:"opacity,omitempty"`
+	Step    int     `xml:"step,attr" json:"step,omitempty"`
+	LineStyle
+	Arrowhead
 }
 
 // Arc defines an elliptical arc
@@ -162,58 +229,304 @@ type Curve struct {
 // <arc xp="55"  yp="10" wp="4" hr="75" a1="0" a2="180"/>
 type Arc struct {
 	Dimension
-	A1      float64 `xml:"a1,attr"`
-	A2      float64 `xml:"a2,attr"`
-	Sp      float64 `xml:"sp,attr"`
-	Opacity float64 `xml:"opacity,attr"`
+	A1      float64 `xml:"a1,attr" json:"a1,omitempty"`
+	A2      float64 `xml:"a2,attr" json:"a2,omitempty"`
+	Sp      float64 `xml:"sp,attr" json:"sp,omitempty"`
+	Opacity float64 `xml:"opacity,attr" json:"opacity,omitempty"`
+	LineStyle
 }
 
 // Polygon defines a polygon, x and y coordinates are specified by
 // strings of space-separated percentages:
 // <polygon xc="10 20 30" yc="30 40 50"/>
 type Polygon struct {
-	XC      string  `xml:"xc,attr"`
-	YC      string  `xml:"yc,attr"`
-	Color   string  `xml:"color,attr"`
-	Opacity float64 `xml:"opacity,attr"`
+	XC      string  `xml:"xc,attr" json:"xc,omitempty"`
+	YC      string  `xml:"yc,attr" json:"yc,omitempty"`
+	Color   string  `xml:"color,attr" json:"color,omitempty"`
+	Opacity float64 `xml:"opacity,attr" json:"opacity,omitempty"`
+	Step    int     `xml:"step,attr" json:"step,omitempty"`
+	Outline
+	Gradient
 }
 
 // Polyline defines a polyline, x and y coordinates are specified by
 // strings of space-separated percentages:
 // <polyline xc="10 20 30" yc="30 40 50"/>
 type Polyline struct {
-	XC      string  `xml:"xc,attr"`
-	YC      string  `xml:"yc,attr"`
-	Sp      float64 `xml:"sp,attr"` // line thickness
-	Color   string  `xml:"color,attr"`
-	Opacity float64 `xml:"opacity,attr"`
+	XC      string  `xml:"xc,attr" json:"xc,omitempty"`
+	YC      string  `xml:"yc,attr" json:"yc,omitempty"`
+	Sp      float64 `xml:"sp,attr" json:"sp,omitempty"` // line thickness
+	Color   string  `xml:"color,attr" json:"color,omitempty"`
+	Opacity float64 `xml:"opacity,attr" json:"opacity,omitempty"`
+	Step    int     `xml:"step,attr" json:"step,omitempty"`
+	LineStyle
+	Arrowhead
 }
 
-// ReadDeck reads the deck description file from a io.Reader
-func ReadDeck(r io.ReadCloser, w, h int) (Deck, error) {
-	var d Deck
-	err := xml.NewDecoder(r).Decode(&d)
-	if d.Canvas.Width == 0 {
-		d.Canvas.Width = w
+// Path describes a shape outlined by SVG-style path data (see ParsePath), in percentage coordinates
+// with y increasing upward; arc radii are percentages of the canvas width.
+// Paths are filled and outlined like polygons:
+// <path d="M 10 10 C 20 30 40 30 50 10 Z" color="steelblue" stroke="black"/>
+type Path struct {
+	D       string  `xml:"d,attr" json:"d,omitempty"` // path data
+	Color   string  `xml:"color,attr" json:"color,omitempty"`
+	Opacity float64 `xml:"opacity,attr" json:"opacity,omitempty"`
+	Step    int     `xml:"step,attr" json:"step,omitempty"`
+	Outline
+	Gradient
+}
+
+// Connector is a line between the edges of two rect, ellipse or image elements of the same slide
+// or group, identified by their ids. Connectors have an arrowhead at their end, unless arrow is "none":
+// <connector from="client" to="server" sp="0.2" arrow="both"/>
+type Connector struct {
+	From    string  `xml:"from,attr" json:"from,omitempty"`       // id of the starting element
+	To      string  `xml:"to,attr" json:"to,omitempty"`           // id of the ending element
+	Sp      float64 `xml:"sp,attr" json:"sp,omitempty"`           // line thickness
+	Color   string  `xml:"color,attr" json:"color,omitempty"`     // line color
+	Opacity float64 `xml:"opacity,attr" json:"opacity,omitempty"` // line opacity
+	Step    int     `xml:"step,attr" json:"step,omitempty"`       // build step at which the element appears
+	LineStyle
+	Arrowhead
+}
+
+// Table describes a table with its upper left corner at (xp, yp).
+// Column widths are percentages of the canvas width; columns without a width
+// share the rest of the table width (wp). The first header rows are bold,
+// alternate body rows are filled with the stripe color, and text wraps within its cell:
+// <table xp="10" yp="80" sp="2" widths="40 20 20" aligns="begin end end" header="1" border="gray">
+//
+//	<tr><td>Region</td><td>Q1</td><td>Q2</td></tr>
+//	<tr><td>North</td><td>120</td><td>135</td></tr>
+//
+// </table>
+type Table struct {
+	CommonAttr
+	Wp     float64    `xml:"wp,attr" json:"wp,omitempty"`         // table width percentage
+	Widths string     `xml:"widths,attr" json:"widths,omitempty"` // column widths: space-separated percentages
+	Aligns string     `xml:"aligns,attr" json:"aligns,omitempty"` // column alignments: space-separated begin, center, end
+	Header int        `xml:"header,attr" json:"header,omitempty"` // number of header rows
+	Border string     `xml:"border,attr" json:"border,omitempty"` // border color
+	Stripe string     `xml:"stripe,attr" json:"stripe,omitempty"` // fill color of alternate body rows
+	Tr     []TableRow `xml:"tr" json:"tr,omitempty"`
+}
+
+// TableRow describes a row of a table
+type TableRow struct {
+	Bg    string      `xml:"bg,attr" json:"bg,omitempty"`       // row fill color
+	Color string      `xml:"color,attr" json:"color,omitempty"` // text color
+	Font  string      `xml:"font,attr" json:"font,omitempty"`
+	Td    []TableCell `xml:"td" json:"td,omitempty"`
+}
+
+// TableCell describes a cell of a table row; its attributes override those of the row and table
+type TableCell struct {
+	Align string `xml:"align,attr" json:"align,omitempty"`
+	Bg    string `xml:"bg,attr" json:"bg,omitempty"`
+	Color string `xml:"color,attr" json:"color,omitempty"`
+	Font  string `xml:"font,attr" json:"font,omitempty"`
+	Text  string `xml:",chardata" json:"text,omitempty"`
+}
+
+// Chart describes a chart of the data in a CSV or TSV file, with the lower left corner
+// of its plot area at (xp, yp). The first column of the file holds the labels
+// (the x values of scatter charts), and each other column a series, named by an optional header row.
+// Type is bar (the default), line, scatter or pie; pie charts show the first series:
+// <chart type="bar" data="sales.csv" xp="10" yp="20" wp="70" hp="50" colors="steelblue orange"/>
+type Chart struct {
+	CommonAttr
+	Wp     float64 `xml:"wp,attr" json:"wp,omitempty"`         // plot width percentage
+	Hp     float64 `xml:"hp,attr" json:"hp,omitempty"`         // plot height percentage
+	Data   string  `xml:"data,attr" json:"data,omitempty"`     // CSV or TSV file name
+	Colors string  `xml:"colors,attr" json:"colors,omitempty"` // series colors: space-separated, e.g. "red rgb(0, 128, 0)"
+	Min    float64 `xml:"min,attr" json:"min,omitempty"`       // lowest value of the scale (default: from the data)
+	Max    float64 `xml:"max,attr" json:"max,omitempty"`       // highest value of the scale (default: from the data)
+	Grid   string  `xml:"grid,attr" json:"grid,omitempty"`     // gridlines: on (default) or off
+	Legend string  `xml:"legend,attr" json:"legend,omitempty"` // legend: on (default) or off
+}
+
+// Group is a set of slide elements, including other groups, drawn scaled about (xp, yp),
+// moved by (tx, ty), and rotated counterclockwise about the moved (xp, yp).
+// Its color, font and opacity are the defaults of its elements;
+// the opacity of elements is also relative to that of the group:
+// <group tx="20" scale="50" rotation="30" xp="50" yp="50" color="red">
+//
+//	<rect xp="50" yp="50" wp="10" hp="10"/>
+//	<text xp="50" yp="40" sp="2" align="center">box</text>
+//
+// </group>
+type Group struct {
+	Tx        float64     `xml:"tx,attr" json:"tx,omitempty"`             // horizontal translation percentage
+	Ty        float64     `xml:"ty,attr" json:"ty,omitempty"`             // vertical translation percentage
+	Scale     float64     `xml:"scale,attr" json:"scale,omitempty"`       // scale percentage
+	Rotation  float64     `xml:"rotation,attr" json:"rotation,omitempty"` // rotation (0-360 degrees)
+	Xp        float64     `xml:"xp,attr" json:"xp,omitempty"`             // X coordinate of the center of scaling and rotation
+	Yp        float64     `xml:"yp,attr" json:"yp,omitempty"`             // Y coordinate of the center of scaling and rotation
+	Color     string      `xml:"color,attr" json:"color,omitempty"`       // default color
+	Font      string      `xml:"font,attr" json:"font,omitempty"`         // default font
+	Opacity   float64     `xml:"opacity,attr" json:"opacity,omitempty"`   // opacity percentage
+	Step      int         `xml:"step,attr" json:"step,omitempty"`         // build step at which the group appears
+	List      []List      `xml:"list" json:"list,omitempty"`
+	Text      []Text      `xml:"text" json:"text,omitempty"`
+	Image     []Image     `xml:"image" json:"image,omitempty"`
+	Ellipse   []Ellipse   `xml:"ellipse" json:"ellipse,omitempty"`
+	Line      []Line      `xml:"line" json:"line,omitempty"`
+	Rect      []Rect      `xml:"rect" json:"rect,omitempty"`
+	Curve     []Curve     `xml:"curve" json:"curve,omitempty"`
+	Arc       []Arc       `xml:"arc" json:"arc,omitempty"`
+	Polygon   []Polygon   `xml:"polygon" json:"polygon,omitempty"`
+	Polyline  []Polyline  `xml:"polyline" json:"polyline,omitempty"`
+	Path      []Path      `xml:"path" json:"path,omitempty"`
+	Connector []Connector `xml:"connector" json:"connector,omitempty"`
+	Table     []Table     `xml:"table" json:"table,omitempty"`
+	Chart     []Chart     `xml:"chart" json:"chart,omitempty"`
+	Group     []Group     `xml:"group" json:"group,omitempty"`
+	Master    bool        `xml:"-" json:"-"` // holds the elements of the slide's template, drawn before the slide's own
+}
+
+// Elements returns the elements of a group as those of a slide
+func (g Group) Elements() Slide {
+	return Slide{
+		List:      g.List,
+		Text:      g.Text,
+		Image:     g.Image,
+		Ellipse:   g.Ellipse,
+		Line:      g.Line,
+		Rect:      g.Rect,
+		Curve:     g.Curve,
+		Arc:       g.Arc,
+		Polygon:   g.Polygon,
+		Polyline:  g.Polyline,
+		Path:      g.Path,
+		Connector: g.Connector,
+		Table:     g.Table,
+		Chart:     g.Chart,
+		Group:     g.Group,
 	}
-	if d.Canvas.Height == 0 {
-		d.Canvas.Height = h
+}
+
+// Steps returns the number of build steps of a slide: the last step at which its elements,
+// or the items of its lists, appear; slides without steps have one
+func Steps(s Slide) int {
+	n := 1
+	step := func(k int) {
+		if k > n {
+			n = k
+		}
 	}
-	r.Close()
-	return d, err
+	for _, e := range s.List {
+		step(e.Step)
+		if e.Build > 0 {
+			step(e.Build + len(e.Li) - 1)
+		}
+	}
+	for _, e := range s.Text {
+		step(e.Step)
+	}
+	for _, e := range s.Image {
+		step(e.Step)
+	}
+	for _, e := range s.Ellipse {
+		step(e.Step)
+	}
+	for _, e := range s.Line {
+		step(e.Step)
+	}
+	for _, e := range s.Rect {
+		step(e.Step)
+	}
+	for _, e := range s.Curve {
+		step(e.Step)
+	}
+	for _, e := range s.Arc {
+		step(e.Step)
+	}
+	for _, e := range s.Polygon {
+		step(e.Step)
+	}
+	for _, e := range s.Polyline {
+		step(e.Step)
+	}
+	for _, e := range s.Path {
+		step(e.Step)
+	}
+	for _, e := range s.Connector {
+		step(e.Step)
+	}
+	for _, e := range s.Table {
+		step(e.Step)
+	}
+	for _, e := range s.Chart {
+		step(e.Step)
+	}
+	for _, g := range s.Group {
+		step(g.Step)
+		step(Steps(g.Elements()))
+	}
+	return n
+}
+
+// ReadDeck reads the deck description file from a io.Reader;
+// included files are relative to the current directory
+func ReadDeck(r io.ReadCloser, w, h int) (Deck, error) {
+	return readDeck(r, "", w, h, ReadOptions{})
 }
 
 // Read reads the deck description file
 func Read(filename string, w, h int) (Deck, error) {
+	return ReadWithOptions(filename, w, h, ReadOptions{})
+}
+
+// readDeck reads a deck description in XML, JSON or YAML, and applies templates.
+// XML markup, and that of included files, is checked in strict mode, and its includes are expanded
+// relative to the named file.
+func readDeck(r io.ReadCloser, filename string, w, h int, opts ReadOptions) (Deck, error) {
 	var d Deck
-	if filename == "-" {
-		return ReadDeck(os.Stdin, w, h)
-	}
-	r, err := os.Open(filename)
+	data, err := io.ReadAll(r)
+	r.Close()
 	if err != nil {
 		return d, err
 	}
-	return ReadDeck(r, w, h)
+	var errs MarkupErrors
+	switch inputformat(filename, data) {
+	case "json":
+		err = decodeJSON(data, &d, opts.Strict)
+	case "yaml":
+		err = decodeYAML(data, &d, opts.Strict)
+	default:
+		if opts.Strict {
+			errs = checkMarkup(data)
+			for i := range errs {
+				errs[i].File = filename
+			}
+		}
+		var ierrs MarkupErrors
+		data, ierrs, err = expandIncludes(data, filename, opts.Strict)
+		errs = append(errs, ierrs...)
+		if err == nil {
+			err = xml.Unmarshal(data, &d)
+		}
+		for i := range d.Slide {
+			flatten(d.Slide[i].Text, d.Slide[i].List, d.Slide[i].Group)
+		}
+		for i := range d.Template {
+			flatten(d.Template[i].Text, d.Template[i].List, d.Template[i].Group)
+		}
+	}
+	if d.Canvas.Width == 0 {
+		d.Canvas.Width = w
+	}
+	if d.Canvas.Height == 0 {
+		d.Canvas.Height = h
+	}
+	if err == nil {
+		err = ApplyTemplates(&d)
+	}
+	// markup errors include syntax errors, with their position
+	if _, syntax := err.(*xml.SyntaxError); len(errs) > 0 && (err == nil || syntax) {
+		return d, errs
+	}
+	return d, err
 }
 
 // Dimen computes the coordinates and size of an object
@@ -0,0 +1,149 @@
+package deck
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// inputformat determines the format of a deck description (xml, json or yaml)
+// by file extension or, failing that, by content: JSON begins with "{",
+// XML with "<"; anything else is taken as YAML.
+func inputformat(filename string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return "json"
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".xml":
+		return "xml"
+	}
+	data = bytes.TrimLeft(data, " \t\r\n\ufeff")
+	switch {
+	case len(data) == 0, data[0] == '<':
+		return "xml"
+	case data[0] == '{':
+		return "json"
+	}
+	return "yaml"
+}
+
+// decodeJSON decodes a deck from JSON; in strict mode, unknown fields are errors.
+// The names of fields are those of the XML elements and attributes:
+// {"canvas": {"width": 1024, "height": 768}, "slide": [{"bg": "black", "text": [{"xp": 10, "yp": 50, "text": "hello"}]}]}
+func decodeJSON(data []byte, d *Deck, strict bool) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+	return dec.Decode(d)
+}
+
+// decodeYAML decodes a deck from YAML, by way of its JSON equivalent;
+// scalars decoded into strings keep their source text, so that text such as 42,
+// yes or 2026-10-01 is not mistyped, or reformatted
+func decodeYAML(data []byte, d *Deck, strict bool) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	v, err := yamlvalue(&doc, reflect.TypeOf(d))
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		return nil
+	}
+	j, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("yaml: %v", err)
+	}
+	if err := decodeJSON(j, d, strict); err != nil {
+		return fmt.Errorf("yaml: %v", strings.TrimPrefix(err.Error(), "json: "))
+	}
+	return nil
+}
+
+// yamlvalue returns the value of a YAML node, to be decoded as JSON into a value of type t
+// (nil if unknown)
+func yamlvalue(n *yaml.Node, t reflect.Type) (interface{}, error) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil, nil
+		}
+		return yamlvalue(n.Content[0], t)
+	case yaml.AliasNode:
+		return yamlvalue(n.Alias, t)
+	case yaml.MappingNode:
+		fields := jsonfields(t)
+		m := map[string]interface{}{}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k := n.Content[i].Value
+			v, err := yamlvalue(n.Content[i+1], fields[k])
+			if err != nil {
+				return nil, err
+			}
+			m[k] = v
+		}
+		return m, nil
+	case yaml.SequenceNode:
+		var et reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			et = t.Elem()
+		}
+		s := make([]interface{}, len(n.Content))
+		for i, c := range n.Content {
+			v, err := yamlvalue(c, et)
+			if err != nil {
+				return nil, err
+			}
+			s[i] = v
+		}
+		return s, nil
+	case yaml.ScalarNode:
+		if t != nil && t.Kind() == reflect.String && n.Tag != "!!null" {
+			return n.Value, nil
+		}
+	}
+	var v interface{}
+	err := n.Decode(&v)
+	return v, err
+}
+
+// jsonfields maps the JSON names of the fields of a struct type,
+// including those of embedded structs, to their types
+func jsonfields(t reflect.Type) map[string]reflect.Type {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			for name, ft := range jsonfields(f.Type) {
+				if _, ok := fields[name]; !ok {
+					fields[name] = ft
+				}
+			}
+			continue
+		}
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
@@ -0,0 +1,136 @@
+package deck
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadFormats(t *testing.T) {
+	xmldeck := `<deck>
+<title>Demo</title>
+<canvas width="1024" height="768"/>
+<template name="base" bg="black"/>
+<slide template="base" duration="2s">
+<text xp="10" yp="50" sp="3">hello &amp; goodbye</text>
+<list xp="10" yp="30" type="bullet"><li>one</li><li color="red">two</li></list>
+<polygon xc="10 20 30" yc="10 20 10" color="red"/>
+</slide>
+</deck>`
+	jsondeck := `{
+	"title": "Demo",
+	"canvas": {"width": 1024, "height": 768},
+	"template": [{"name": "base", "bg": "black"}],
+	"slide": [{
+		"template": "base", "duration": "2s",
+		"text": [{"xp": 10, "yp": 50, "sp": 3, "text": "hello & goodbye"}],
+		"list": [{"xp": 10, "yp": 30, "type": "bullet", "li": [{"text": "one"}, {"text": "two", "color": "red"}]}],
+		"polygon": [{"xc": "10 20 30", "yc": "10 20 10", "color": "red"}]
+	}]
+}`
+	yamldeck := `title: Demo
+canvas: {width: 1024, height: 768}
+template:
+  - name: base
+    bg: black
+slide:
+  - template: base
+    duration: 2s
+    text:
+      - {xp: 10, yp: 50, sp: 3, text: hello & goodbye}
+    list:
+      - xp: 10
+        yp: 30
+        type: bullet
+        li:
+          - text: one
+          - {text: two, color: red}
+    polygon:
+      - {xc: 10 20 30, yc: 10 20 10, color: red}
+`
+	want, err := ReadDeck(io.NopCloser(strings.NewReader(xmldeck)), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = unmarkup(want)
+	dir := t.TempDir()
+	for _, test := range []struct {
+		name, data string
+	}{
+		{"deck.json", jsondeck},
+		{"deck.yaml", yamldeck},
+	} {
+		// by extension
+		file := filepath.Join(dir, test.name)
+		if err := os.WriteFile(file, []byte(test.data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		d, err := Read(file, 0, 0)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(d, want) {
+			t.Errorf("%s: got\n%+v\nwant\n%+v", test.name, d, want)
+		}
+		// by content
+		d, err = ReadDeck(io.NopCloser(strings.NewReader(test.data)), 0, 0)
+		if err != nil || !reflect.DeepEqual(d, want) {
+			t.Errorf("%s, read by content: got %v\n%+v\nwant\n%+v", test.name, err, d, want)
+		}
+	}
+
+	// unknown fields are errors in strict mode
+	_, err = ReadDeckOptions(io.NopCloser(strings.NewReader(`{"slide": [{"txt": []}]}`)), 0, 0, ReadOptions{Strict: true})
+	if err == nil || !strings.Contains(err.Error(), `unknown field "txt"`) {
+		t.Errorf("strict JSON: got %v", err)
+	}
+	_, err = ReadDeckOptions(io.NopCloser(strings.NewReader("slide:\n  - txt: 1\n")), 0, 0, ReadOptions{Strict: true})
+	if err == nil || !strings.Contains(err.Error(), `yaml: unknown field "txt"`) {
+		t.Errorf("strict YAML: got %v", err)
+	}
+}
+
+func TestReadYAMLScalars(t *testing.T) {
+	yamldeck := `title: 2026
+date: 2026-10-01
+canvas: {width: 1024, height: 768}
+slide:
+  - duration: 10
+    text:
+      - {xp: 10, yp: 50, text: 42}
+      - {xp: 10, yp: 40, text: yes}
+      - {xp: 10, yp: 30, text: 1.50}
+    table:
+      - tr:
+          - td: [{text: 3}, {text: true}, {text: 2026-10-01}]
+`
+	d, err := ReadDeck(io.NopCloser(strings.NewReader(yamldeck)), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Title != "2026" || d.Date != "2026-10-01" {
+		t.Errorf("title, date: got %q, %q", d.Title, d.Date)
+	}
+	if d.Canvas.Width != 1024 || d.Canvas.Height != 768 {
+		t.Errorf("canvas: got %v", d.Canvas)
+	}
+	s := d.Slide[0]
+	if s.Duration != "10" {
+		t.Errorf("duration: got %q", s.Duration)
+	}
+	var got []string
+	for _, e := range s.Text {
+		got = append(got, e.Tdata)
+	}
+	for _, c := range s.Table[0].Tr[0].Td {
+		got = append(got, c.Text)
+	}
+	want := []string{"42", "yes", "1.50", "3", "true", "2026-10-01"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("text: got %q, want %q", got, want)
+	}
+}
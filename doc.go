@@ -11,7 +11,7 @@ Here are the elements of a deck:
 	deck: enclosing element
 	canvas: describe the dimensions of the drawing canvas, one per deck
 	metadata elements: title, creator, date, publisher, subject, description
-	slide: within a deck, any number of slides, specify the slide duration, gradient colors, background and text colors.
+	slide: within a deck, any number of slides, specify the slide duration and transition, gradient colors, background and text colors.
 
 within slides an number of:
 
@@ -25,6 +25,11 @@ within slides an number of:
 	arc: elliptical arc
 	polygon: polygon
 	polyline: polyline
+	path: lines, Bezier curves and arcs, as in SVG path data
+	connector: a line, with arrowheads, between the edges of two identified elements
+	table: rows of cells, with headers, column widths and alignments
+	chart: bar, line, scatter or pie chart of the data in a CSV or TSV file
+	group: a set of elements, moved, scaled and rotated together, with shared color, font and opacity
 
 Markup
 
@@ -68,6 +73,25 @@ The list, text, rect, and ellipse elements have common attributes:
 	font: "sans", "serif", "mono", "symbol"
 	link: url
 
+The rect, ellipse and polygon elements are filled with their color (or fill: a color, or "none"),
+and outlined with stroke (the outline color) and strokewidth (a percentage of the canvas width).
+Their outlines, and lines, curves, arcs and polylines, are stroked with:
+
+	dash: dash and gap lengths, in multiples of the stroke width ("4 2")
+	linecap: "butt", "round", "square"
+	linejoin: "miter", "round", "bevel"
+
+Slides, and the rect, ellipse, polygon and path elements, may be filled with a gradient:
+
+	gradcolor1, gradcolor2: the colors, from the start of the gradient to its end
+	gp: percentage of the shape covered by the transition (100 by default)
+	gradangle: degrees counterclockwise from top to bottom (90 runs from left to right)
+	gradtype: "linear" (the default), or "radial" from the center outward
+
+Slides may be built up in steps. Elements with a step attribute appear at that build step,
+and a list with a build attribute shows its items one per step, starting at that step.
+Elements from earlier steps are dimmed to the slide's dim opacity, if specified.
+
 Layout
 
 All layout in done in terms of percentages, using a coordinate system with the origin (0%, 0%) at the lower left.
@@ -0,0 +1,264 @@
+package deck
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// layout describes the canonical attribute order of an element,
+// which attributes are numbers, and whether the element holds text
+type layout struct {
+	attrs   []string
+	numeric map[string]bool
+	text    bool
+}
+
+// layouts are derived from the xml tags of the deck types, by element name
+var layouts = map[string]*layout{}
+
+// numlists are attributes holding lists of numbers
+var numlists = map[string]bool{"xc": true, "yc": true}
+
+func init() {
+	addlayout("deck", reflect.TypeOf(Deck{}))
+	layouts["include"] = &layout{attrs: []string{"file"}}
+}
+
+// addlayout adds the layout of an element of type t, and of its children
+func addlayout(name string, t reflect.Type) {
+	if _, ok := layouts[name]; ok {
+		return
+	}
+	l := &layout{numeric: map[string]bool{}}
+	layouts[name] = l
+	for _, f := range xmlfields(reflect.New(t).Elem()) {
+		ft := f.v.Type()
+		switch f.kind {
+		case "attr":
+			l.attrs = append(l.attrs, f.name)
+			switch ft.Kind() {
+			case reflect.Float32, reflect.Float64, reflect.Int, reflect.Int32, reflect.Int64:
+				l.numeric[f.name] = true
+			}
+		case "chardata":
+			l.text = true
+		case "element":
+			if ft.Kind() == reflect.Slice {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addlayout(f.name, ft)
+			} else {
+				layouts[f.name] = &layout{text: true}
+			}
+		}
+	}
+}
+
+// node is a parsed element, text, comment, processing instruction or directive
+type node struct {
+	tok      xml.Token
+	children []*node
+	blank    bool // preceded by a blank line
+}
+
+// Format returns deck markup in canonical layout: elements are indented by tabs,
+// attributes are in a fixed order, and numbers are normalized. Comments and
+// blank lines between elements are kept, as is the content of text elements.
+func Format(src []byte) ([]byte, error) {
+	root, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	for _, n := range root.children {
+		if _, ok := n.tok.(xml.CharData); ok {
+			continue
+		}
+		printnode(&buf, n, 0)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// parse builds the tree of nodes from markup
+func parse(src []byte) (*node, error) {
+	dec := xml.NewDecoder(bytes.NewReader(src))
+	root := &node{}
+	stack := []*node{root}
+	blank := false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		parent := stack[len(stack)-1]
+		switch t := tok.(type) {
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+			continue
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) == 0 && bytes.Count(t, []byte("\n")) > 1 {
+				blank = true
+			}
+		}
+		n := &node{tok: xml.CopyToken(tok), blank: blank}
+		if _, ok := tok.(xml.CharData); !ok {
+			blank = false
+		}
+		parent.children = append(parent.children, n)
+		if _, ok := tok.(xml.StartElement); ok {
+			stack = append(stack, n)
+			blank = false
+		}
+	}
+	return root, nil
+}
+
+// hastext determines if an element holds text, either by its layout,
+// or because it contains non-space character data
+func (n *node) hastext() bool {
+	if l, ok := layouts[n.tok.(xml.StartElement).Name.Local]; ok && l.text {
+		return true
+	}
+	for _, c := range n.children {
+		if t, ok := c.tok.(xml.CharData); ok && len(bytes.TrimSpace(t)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// printnode writes a node indented by depth tabs
+func printnode(w *bytes.Buffer, n *node, depth int) {
+	indent := strings.Repeat("\t", depth)
+	switch t := n.tok.(type) {
+	case xml.Comment:
+		w.WriteString(indent + "<!--" + string(t) + "-->")
+	case xml.ProcInst:
+		w.WriteString(indent + "<?" + t.Target + " " + string(t.Inst) + "?>")
+	case xml.Directive:
+		w.WriteString(indent + "<!" + string(t) + ">")
+	case xml.StartElement:
+		name := t.Name.Local
+		w.WriteString(indent)
+		starttag(w, t)
+		if n.hastext() {
+			if len(n.children) == 0 {
+				unclose(w)
+				return
+			}
+			inline(w, n.children)
+			w.WriteString("</" + name + ">")
+			return
+		}
+		first := true
+		for _, c := range n.children {
+			if _, ok := c.tok.(xml.CharData); ok {
+				continue
+			}
+			if c.blank && !first {
+				w.WriteByte('\n')
+			}
+			w.WriteByte('\n')
+			printnode(w, c, depth+1)
+			first = false
+		}
+		if first {
+			unclose(w)
+			return
+		}
+		w.WriteString("\n" + indent + "</" + name + ">")
+	}
+}
+
+// unclose turns the start tag just written into an empty element tag
+func unclose(w *bytes.Buffer) {
+	w.Truncate(w.Len() - 1)
+	w.WriteString("/>")
+}
+
+// inline writes the content of a text element as is
+func inline(w *bytes.Buffer, nodes []*node) {
+	for _, n := range nodes {
+		switch t := n.tok.(type) {
+		case xml.CharData:
+			w.WriteString(textesc.Replace(string(t)))
+		case xml.Comment:
+			w.WriteString("<!--" + string(t) + "-->")
+		case xml.StartElement:
+			starttag(w, t)
+			inline(w, n.children)
+			w.WriteString("</" + t.Name.Local + ">")
+		}
+	}
+}
+
+// starttag writes a start tag, with its attributes in canonical order
+func starttag(w *bytes.Buffer, t xml.StartElement) {
+	name := t.Name.Local
+	w.WriteString("<" + name)
+	l := layouts[name]
+	attrs := append([]xml.Attr{}, t.Attr...)
+	if l != nil {
+		rank := map[string]int{}
+		for i, a := range l.attrs {
+			rank[a] = i + 1
+		}
+		// known attributes first, in layout order; then the others as written
+		sorted := make([]xml.Attr, 0, len(attrs))
+		for _, a := range l.attrs {
+			for _, at := range attrs {
+				if at.Name.Local == a && at.Name.Space == "" {
+					sorted = append(sorted, at)
+				}
+			}
+		}
+		for _, at := range attrs {
+			if rank[at.Name.Local] == 0 || at.Name.Space != "" {
+				sorted = append(sorted, at)
+			}
+		}
+		attrs = sorted
+	}
+	for _, a := range attrs {
+		v := a.Value
+		if l != nil && l.numeric[a.Name.Local] {
+			v = number(v)
+		} else if numlists[a.Name.Local] {
+			v = numbers(v)
+		}
+		name := a.Name.Local
+		if a.Name.Space != "" {
+			name = a.Name.Space + ":" + name
+		}
+		w.WriteString(" " + name + `="` + attresc.Replace(v) + `"`)
+	}
+	w.WriteString(">")
+}
+
+// number normalizes a number: "10.50" is "10.5", ".5" is "0.5";
+// values that are not numbers are unchanged
+func number(s string) string {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return s
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// numbers normalizes a space-separated list of numbers
+func numbers(s string) string {
+	fields := strings.Fields(s)
+	for i, f := range fields {
+		fields[i] = number(f)
+	}
+	return strings.Join(fields, " ")
+}
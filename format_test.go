@@ -0,0 +1,48 @@
+package deck
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	src := `<?xml version="1.0"?>
+<!-- a deck -->
+<deck><canvas height="768.0" width="1024"/>
+  <slide fg="white"   bg="black" >
+     <text sp="3.50" yp=".5" xp="10" align="end">Sales are <b>up</b>  &amp; more</text>
+
+
+     <!-- shapes -->
+     <polygon color="red" xc="10.0  20 30" yc="1 2 3.000"/><rect data-x="1" xp="50" yp="50"></rect>
+     <list><li color="red">one</li></list>
+  </slide>
+</deck>`
+	want := `<?xml version="1.0"?>
+<!-- a deck -->
+<deck>
+	<canvas width="1024" height="768"/>
+	<slide bg="black" fg="white">
+		<text xp="10" yp="0.5" sp="3.5" align="end">Sales are <b>up</b>  &amp; more</text>
+
+		<!-- shapes -->
+		<polygon xc="10 20 30" yc="1 2 3" color="red"/>
+		<rect xp="50" yp="50" data-x="1"/>
+		<list>
+			<li color="red">one</li>
+		</list>
+	</slide>
+</deck>
+`
+	got, err := Format([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	again, err := Format(got)
+	if err != nil || string(again) != string(got) {
+		t.Errorf("formatting is not idempotent: got\n%s", again)
+	}
+	if _, err := Format([]byte("<deck><slide></deck>")); err == nil {
+		t.Error("malformed markup: no error")
+	}
+}
@@ -6,7 +6,7 @@ Initialization of the package specifies the io.Writer destination for the genera
 along with the width and height of the slides's canvas. (Speciying (0,0) allows the rendering client
 to use default dimensions).
 
-Each deck element (text, list, image, rect, ellipse, line, curve, arc, and polygon) are supported.
+Each deck element (text, list, table, image, rect, ellipse, line, curve, arc, polygon and polyline) are supported.
 Slides use a percentage-based coordinate system (origin at the lower left corner,
 x increasing left to right, 0-100%, y increasing  upwards, 0-100%).
 
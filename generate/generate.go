@@ -4,6 +4,7 @@ package generate
 import (
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/ajstarks/deck"
 )
@@ -17,6 +18,7 @@ const (
 	linefmt     = `<line xp1="%.2f" yp1="%.2f" xp2="%.2f" yp2="%.2f" sp="%.2f" opacity="%.2f" color="%s"/>`
 	curvefmt    = `<curve xp1="%.2f" yp1="%.2f" xp2="%.2f" yp2="%.2f" xp3="%.2f" yp3="%.2f" sp="%.2f" opacity="%.2f" color="%s"/>`
 	polygonfmt  = `<polygon xc="%s" yc="%s" opacity="%.2f" color="%s"/>`
+	polylinefmt = `<polyline xc="%s" yc="%s" sp="%.2f" opacity="%.2f" color="%s"/>`
 	textfmt     = `<text xp="%.2f" yp="%.2f" sp="%.2f" align="%s" wp="%.2f" font="%s" opacity="%.2f" color="%s" type="%s">%s</text>`
 	textlinkfmt = `<text xp="%.2f" yp="%.2f" sp="%.2f" align="%s" wp="%.2f" font="%s" opacity="%.2f" color="%s" type="%s" link="%s">%s</text>`
 	textrotfmt  = `<text xp="%.2f" yp="%.2f" sp="%.2f" align="%s" wp="%.2f" font="%s" opacity="%.2f" color="%s" type="%s" link="%s" rotation="%.2f">%s</text>`
@@ -24,6 +26,11 @@ const (
 	listfmt     = `<list type="%s" xp="%.2f" yp="%.2f" sp="%.2f" lp="%.2f" wp="%.2f" font="%s" color="%s">`
 	lifmt       = `<li>%s</li>`
 	closelist   = `</list>`
+	tablefmt    = `<table xp="%.2f" yp="%.2f" sp="%.2f" widths="%s" aligns="%s" header="%d" font="%s" color="%s" border="%s" stripe="%s">`
+	trfmt       = `<tr>`
+	tdfmt       = `<td>%s</td>`
+	closetr     = `</tr>`
+	closetable  = `</table>`
 	slidefmt    = `<slide>`
 	slidebg     = `<slide bg="%s">`
 	slidebgfg   = `<slide bg="%s" fg="%s">`
@@ -110,6 +117,11 @@ func (p *Deck) polygon(poly deck.Polygon) {
 	fmt.Fprintf(p.dest, polygonfmt, poly.XC, poly.YC, poly.Opacity, poly.Color)
 }
 
+// polyline makes polyline markup from the polyline structure.
+func (p *Deck) polyline(poly deck.Polyline) {
+	fmt.Fprintf(p.dest, polylinefmt, poly.XC, poly.YC, poly.Sp, poly.Opacity, poly.Color)
+}
+
 // text makes text markup from the deck text structure.
 func (p *Deck) text(t deck.Text) {
 	fmt.Fprintf(p.dest, textfmt, t.Xp, t.Yp, t.Sp, t.Align, t.Wp, t.Font, t.Opacity, t.Color, t.Type, t.Tdata)
@@ -282,6 +294,26 @@ func (p *Deck) List(x, y, size, spacing, wrap float64, items []string, ltype, fo
 	p.list(l, items, ltype, font, color)
 }
 
+// Table makes a table with its upper left corner at (x,y), with the specified font, size and color.
+// widths are the column widths and aligns the column alignments (begin, center, end);
+// the first header rows are headings. Cell borders are drawn in the border color,
+// and alternate rows are filled with the stripe color, if specified.
+func (p *Deck) Table(x, y, size float64, widths []float64, aligns []string, rows [][]string, header int, font, color, border, stripe string) {
+	w := make([]string, len(widths))
+	for i, v := range widths {
+		w[i] = fmt.Sprintf("%.2f", v)
+	}
+	fmt.Fprintf(p.dest, tablefmt, x, y, size, strings.Join(w, " "), strings.Join(aligns, " "), header, font, color, border, stripe)
+	for _, row := range rows {
+		fmt.Fprint(p.dest, trfmt)
+		for _, s := range row {
+			fmt.Fprintf(p.dest, tdfmt, s)
+		}
+		fmt.Fprint(p.dest, closetr)
+	}
+	fmt.Fprintln(p.dest, closetable)
+}
+
 // Square makes a square, centered at (x,y), with width w, at the specified color and optional opacity.
 func (p *Deck) Square(x, y, w float64, color string, opacity ...float64) {
 	r := deck.Rect{}
@@ -396,6 +428,19 @@ func (p *Deck) Polygon(x, y []float64, color string, opacity ...float64) {
 	p.polygon(poly)
 }
 
+// Polyline makes connected lines with the specified color (with optional opacity),
+// with coordinates in x and y slices; thickness is size.
+func (p *Deck) Polyline(x, y []float64, size float64, color string, opacity ...float64) {
+	xc, yc := Polycoord(x, y)
+	poly := deck.Polyline{XC: xc, YC: yc, Sp: size, Color: color}
+	if len(opacity) > 0 {
+		poly.Opacity = opacity[0]
+	} else {
+		poly.Opacity = 100
+	}
+	p.polyline(poly)
+}
+
 // Polycoord converts slices of coordinates to strings.
 func Polycoord(px, py []float64) (string, string) {
 	var xc, yc string
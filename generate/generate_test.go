@@ -155,6 +155,15 @@ func BenchmarkPolygon(b *testing.B) {
 	canvas.EndSlide()
 }
 
+func BenchmarkPolyline(b *testing.B) {
+	canvas.StartSlide()
+	for i := 0; i < b.N; i++ {
+		px, py := rp(100, 100, 10, 5)
+		canvas.Polyline(px, py, 0.5, "red", 100)
+	}
+	canvas.EndSlide()
+}
+
 func BenchmarkImage(b *testing.B) {
 	canvas.StartSlide("gray")
 	y := 50.0
@@ -188,3 +197,12 @@ func BenchmarkText(b *testing.B) {
 	canvas.Code(35, 39, hellorun, 2, 40, "rgb(127,0,0)")
 	canvas.EndSlide()
 }
+
+func BenchmarkTable(b *testing.B) {
+	rows := [][]string{{"Region", "Q1", "Q2"}, {"North", "120", "135"}, {"South", "98", "104"}}
+	canvas.StartSlide()
+	for i := 0; i < b.N; i++ {
+		canvas.Table(10, 80, 2, []float64{40, 20, 20}, []string{"begin", "end", "end"}, rows, 1, "sans", "black", "gray", "rgb(240,240,240)")
+	}
+	canvas.EndSlide()
+}
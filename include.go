@@ -0,0 +1,197 @@
+package deck
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// includer expands <include file="..."/> elements.
+// At the deck level, the slides and templates of the included file are inserted;
+// at the slide level, the elements of its slides. An included file may
+// contain a deck, or a single slide.
+type includer struct {
+	rootdir string          // directory of the including deck
+	chain   []string        // files being included, outermost first
+	strict  bool            // check the markup of included files
+	checked map[string]bool // included files already checked
+	errs    MarkupErrors    // markup errors of included files
+}
+
+// expandIncludes returns deck markup with its includes expanded;
+// included files are relative to the named file. In strict mode,
+// the markup errors of the included files are returned, each naming its file.
+func expandIncludes(data []byte, filename string, strict bool) ([]byte, MarkupErrors, error) {
+	if !bytes.Contains(data, []byte("<include")) {
+		return data, nil, nil
+	}
+	name := filepath.Clean(filename)
+	if filename == "" {
+		name = "deck"
+	}
+	in := &includer{rootdir: filepath.Dir(filename), chain: []string{name}, strict: strict, checked: map[string]bool{}}
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+	if err := in.walk(enc, data, filename, nil); err != nil {
+		return nil, in.errs, err
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, in.errs, err
+	}
+	return buf.Bytes(), in.errs, nil
+}
+
+// errorf reports an error, naming the include chain
+func (in *includer) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s: %s", strings.Join(in.chain, " -> "), fmt.Sprintf(format, args...))
+}
+
+// include writes the contents of the file to enc,
+// at the location described by the host element path
+func (in *includer) include(enc *xml.Encoder, from, file string, host []string) error {
+	if file == "" {
+		return in.errorf("include without a file")
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(filepath.Dir(from), file)
+	}
+	for _, f := range in.chain {
+		if f == file {
+			in.chain = append(in.chain, file)
+			return in.errorf("include cycle")
+		}
+	}
+	in.chain = append(in.chain, file)
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return in.errorf("%v", err)
+	}
+	if in.strict && !in.checked[file] {
+		in.checked[file] = true
+		for _, e := range checkMarkup(data) {
+			e.File = file
+			in.errs = append(in.errs, e)
+		}
+	}
+	if err := in.walk(enc, data, file, host); err != nil {
+		return err
+	}
+	in.chain = in.chain[:len(in.chain)-1]
+	return nil
+}
+
+// walk copies the tokens of a file to enc, expanding includes.
+// The whole file is copied when host is nil; otherwise, only the
+// content that belongs within the host element path (deck or deck/slide).
+func (in *includer) walk(enc *xml.Encoder, data []byte, filename string, host []string) error {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var path []string
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return in.errorf("%v", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			// a file containing a single slide is treated as a deck of one slide
+			if len(path) == 0 && t.Name.Local == "slide" {
+				path = append(path, "deck")
+			}
+			path = append(path, t.Name.Local)
+			if t.Name.Local == "include" && (pathis(path, "deck", "include") || pathis(path, "deck", "slide", "include")) {
+				if err := in.include(enc, filename, attrvalue(t, "file"), path[:len(path)-1]); err != nil {
+					return err
+				}
+				if err := dec.Skip(); err != nil {
+					return in.errorf("%v", err)
+				}
+				path = path[:len(path)-1]
+				continue
+			}
+			if host != nil {
+				tok = in.relocate(t, filename)
+			}
+		case xml.ProcInst, xml.Directive:
+			if host != nil {
+				continue
+			}
+		}
+		if copyable(path, host) {
+			if err := enc.EncodeToken(xml.CopyToken(tok)); err != nil {
+				return in.errorf("%v", err)
+			}
+		}
+		if _, ok := tok.(xml.EndElement); ok {
+			path = path[:len(path)-1]
+		}
+	}
+}
+
+// copyable determines if content at path is included in host
+func copyable(path, host []string) bool {
+	switch {
+	case host == nil:
+		return true
+	case len(host) == 1: // deck level: slides and templates
+		return len(path) >= 2 && (path[1] == "slide" || path[1] == "template")
+	default: // slide level: slide elements
+		return len(path) >= 3 && path[1] == "slide"
+	}
+}
+
+// relocate makes the file names of images, text and chart data in an included file
+// relative to the directory of the including deck
+func (in *includer) relocate(t xml.StartElement, filename string) xml.StartElement {
+	var attr string
+	switch t.Name.Local {
+	case "image":
+		attr = "name"
+	case "text":
+		attr = "file"
+	case "chart":
+		attr = "data"
+	default:
+		return t
+	}
+	dir, err := filepath.Rel(in.rootdir, filepath.Dir(filename))
+	if err != nil || dir == "." {
+		return t
+	}
+	t = t.Copy()
+	for i, a := range t.Attr {
+		if a.Name.Local == attr && a.Value != "" && !filepath.IsAbs(a.Value) && !strings.Contains(a.Value, "://") {
+			t.Attr[i].Value = filepath.Join(dir, a.Value)
+		}
+	}
+	return t
+}
+
+// pathis determines if an element path matches the names
+func pathis(path []string, names ...string) bool {
+	if len(path) != len(names) {
+		return false
+	}
+	for i := range path {
+		if path[i] != names[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// attrvalue returns the value of the named attribute
+func attrvalue(t xml.StartElement, name string) string {
+	for _, a := range t.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
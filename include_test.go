@@ -0,0 +1,59 @@
+package deck
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInclude(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.xml": `<deck>
+<title>Main</title>
+<slide><text xp="10" yp="90">one</text></slide>
+<include file="lib/legal.xml"/>
+<slide>
+  <include file="lib/footer.xml"/>
+  <text xp="10" yp="50">three</text>
+</slide>
+</deck>`,
+		"lib/legal.xml": `<deck><title>Legal</title><slide bg="black"><text xp="50" yp="50">legal</text></slide></deck>`,
+		"lib/footer.xml": `<slide>
+  <image xp="90" yp="5" width="10" height="10" name="logo.png"/>
+  <text xp="5" yp="5">footer</text>
+</slide>`,
+		"cycle.xml": `<deck><slide><include file="lib/a.xml"/></slide></deck>`,
+		"lib/a.xml": `<slide><include file="b.xml"/></slide>`,
+		"lib/b.xml": `<slide><include file="a.xml"/></slide>`,
+	}
+	for name, content := range files {
+		os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755)
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d, err := Read(filepath.Join(dir, "main.xml"), 100, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Title != "Main" || len(d.Slide) != 3 {
+		t.Fatalf("got title %q and %d slides, want Main and 3", d.Title, len(d.Slide))
+	}
+	if d.Slide[1].Bg != "black" || d.Slide[1].Text[0].Tdata != "legal" {
+		t.Errorf("included slide: %+v", d.Slide[1])
+	}
+	s := d.Slide[2]
+	if len(s.Text) != 2 || s.Text[0].Tdata != "footer" || s.Text[1].Tdata != "three" {
+		t.Errorf("slide elements: %+v", s.Text)
+	}
+	if len(s.Image) != 1 || s.Image[0].Name != filepath.Join("lib", "logo.png") {
+		t.Errorf("image name not relative to the deck: %+v", s.Image)
+	}
+
+	_, err = Read(filepath.Join(dir, "cycle.xml"), 100, 100)
+	if err == nil || !strings.Contains(err.Error(), "a.xml -> ") || !strings.Contains(err.Error(), "include cycle") {
+		t.Errorf("cycle: got %v", err)
+	}
+}
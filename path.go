@@ -0,0 +1,205 @@
+package deck
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PathCmd is a command of a path, with absolute coordinates:
+// M (move) and L (line) take x y, Q (quadratic Bezier) x1 y1 x y,
+// C (cubic Bezier) x1 y1 x2 y2 x y, A (elliptical arc) rx ry rotation large sweep x y,
+// and Z (close) takes nothing.
+type PathCmd struct {
+	Cmd  byte
+	Args []float64
+}
+
+// String returns the command as path data
+func (c PathCmd) String() string {
+	s := []string{string(c.Cmd)}
+	for _, v := range c.Args {
+		s = append(s, strconv.FormatFloat(v, 'f', -1, 64))
+	}
+	return strings.Join(s, " ")
+}
+
+// pathargs is the number of arguments of each path command
+var pathargs = map[byte]int{'M': 2, 'L': 2, 'H': 1, 'V': 1, 'C': 6, 'S': 4, 'Q': 4, 'T': 2, 'A': 7, 'Z': 0}
+
+// pathscanner reads the commands and numbers of path data
+type pathscanner struct {
+	s string
+	i int
+}
+
+func (p *pathscanner) skip() {
+	for p.i < len(p.s) && strings.IndexByte(" \t\r\n,", p.s[p.i]) >= 0 {
+		p.i++
+	}
+}
+
+func (p *pathscanner) digits() bool {
+	start := p.i
+	for p.i < len(p.s) && p.s[p.i] >= '0' && p.s[p.i] <= '9' {
+		p.i++
+	}
+	return p.i > start
+}
+
+func (p *pathscanner) errorf(format string, args ...interface{}) error {
+	rest := p.s[p.i:]
+	switch {
+	case rest == "":
+		return fmt.Errorf("bad path: %s at end", fmt.Sprintf(format, args...))
+	case len(rest) > 10:
+		rest = rest[:10] + "..."
+	}
+	return fmt.Errorf("bad path: %s at %q", fmt.Sprintf(format, args...), rest)
+}
+
+// number reads a number: "-1.5e2", ".5", and "1.5.5" (1.5 followed by .5)
+func (p *pathscanner) number() (float64, error) {
+	p.skip()
+	start := p.i
+	if p.i < len(p.s) && (p.s[p.i] == '+' || p.s[p.i] == '-') {
+		p.i++
+	}
+	whole := p.digits()
+	frac := false
+	if p.i < len(p.s) && p.s[p.i] == '.' {
+		p.i++
+		frac = p.digits()
+	}
+	if !whole && !frac {
+		p.i = start
+		return 0, p.errorf("want a number")
+	}
+	if p.i < len(p.s) && (p.s[p.i] == 'e' || p.s[p.i] == 'E') {
+		e := p.i
+		p.i++
+		if p.i < len(p.s) && (p.s[p.i] == '+' || p.s[p.i] == '-') {
+			p.i++
+		}
+		if !p.digits() {
+			p.i = e
+		}
+	}
+	return strconv.ParseFloat(p.s[start:p.i], 64)
+}
+
+// flag reads an arc flag, which need not be separated from what follows
+func (p *pathscanner) flag() (float64, error) {
+	p.skip()
+	if p.i < len(p.s) && (p.s[p.i] == '0' || p.s[p.i] == '1') {
+		p.i++
+		return float64(p.s[p.i-1] - '0'), nil
+	}
+	return 0, p.errorf("want an arc flag")
+}
+
+// ParsePath parses SVG-style path data: the commands M (move), L (line), H and V (horizontal and vertical lines),
+// C and S (cubic Beziers), Q and T (quadratic Beziers), A (elliptical arc) and Z (close),
+// absolute in upper case and relative to the current point in lower case.
+// The commands are returned with absolute coordinates, with H and V converted to L, S to C, and T to Q.
+func ParsePath(d string) ([]PathCmd, error) {
+	var cmds []PathCmd
+	var cx, cy, sx, sy float64 // current point and start of the subpath
+	var lx, ly float64         // last control point, for S and T
+	var prev, cmd byte
+	p := &pathscanner{s: d}
+	for p.skip(); p.i < len(p.s); p.skip() {
+		c := p.s[p.i]
+		if _, ok := pathargs[c&^0x20]; ok {
+			cmd = c
+			p.i++
+		} else if cmd == 0 {
+			return nil, p.errorf("want a command")
+		}
+		if len(cmds) == 0 && cmd != 'M' && cmd != 'm' {
+			return nil, p.errorf("want a move")
+		}
+		upper := cmd &^ 0x20
+		args := make([]float64, pathargs[upper])
+		for i := range args {
+			var err error
+			if upper == 'A' && (i == 3 || i == 4) {
+				args[i], err = p.flag()
+			} else {
+				args[i], err = p.number()
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		// make coordinates absolute
+		if cmd != upper {
+			switch upper {
+			case 'H':
+				args[0] += cx
+			case 'V':
+				args[0] += cy
+			case 'A':
+				args[5] += cx
+				args[6] += cy
+			default:
+				for i := 0; i < len(args); i += 2 {
+					args[i] += cx
+					args[i+1] += cy
+				}
+			}
+		}
+		switch upper {
+		case 'M':
+			cmds = append(cmds, PathCmd{'M', args})
+			sx, sy = args[0], args[1]
+		case 'L':
+			cmds = append(cmds, PathCmd{'L', args})
+		case 'H':
+			args = []float64{args[0], cy}
+			cmds = append(cmds, PathCmd{'L', args})
+		case 'V':
+			args = []float64{cx, args[0]}
+			cmds = append(cmds, PathCmd{'L', args})
+		case 'C':
+			cmds = append(cmds, PathCmd{'C', args})
+			lx, ly = args[2], args[3]
+		case 'S':
+			x1, y1 := cx, cy
+			if prev == 'C' || prev == 'S' {
+				x1, y1 = 2*cx-lx, 2*cy-ly
+			}
+			args = append([]float64{x1, y1}, args...)
+			cmds = append(cmds, PathCmd{'C', args})
+			lx, ly = args[2], args[3]
+		case 'Q':
+			cmds = append(cmds, PathCmd{'Q', args})
+			lx, ly = args[0], args[1]
+		case 'T':
+			x1, y1 := cx, cy
+			if prev == 'Q' || prev == 'T' {
+				x1, y1 = 2*cx-lx, 2*cy-ly
+			}
+			args = append([]float64{x1, y1}, args...)
+			cmds = append(cmds, PathCmd{'Q', args})
+			lx, ly = x1, y1
+		case 'A':
+			cmds = append(cmds, PathCmd{'A', args})
+		case 'Z':
+			cmds = append(cmds, PathCmd{'Z', nil})
+			args = []float64{sx, sy}
+		}
+		cx, cy = args[len(args)-2], args[len(args)-1]
+		prev = upper
+		// coordinates following a move are lines; nothing follows a close without a command
+		switch cmd {
+		case 'M':
+			cmd = 'L'
+		case 'm':
+			cmd = 'l'
+		case 'Z', 'z':
+			cmd = 0
+		}
+	}
+	return cmds, nil
+}
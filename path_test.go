@@ -0,0 +1,36 @@
+package deck
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		d    string
+		want string
+	}{
+		{"M 10 10 L 20 10 Z", "[M 10 10 L 20 10 Z]"},
+		{"m10,10 10,0 v5 h-5z", "[M 10 10 L 20 10 L 20 15 L 15 15 Z]"},
+		{"M0 0C0 10 10 10 10 0S20-10 20 0", "[M 0 0 C 0 10 10 10 10 0 C 10 -10 20 -10 20 0]"},
+		{"M0 0 Q5 10 10 0 T20 0", "[M 0 0 Q 5 10 10 0 Q 15 -10 20 0]"},
+		{"M1 1 a5 5 0 01 10 0", "[M 1 1 A 5 5 0 0 1 11 1]"},
+		{"M.5.5 1e1-1.5", "[M 0.5 0.5 L 10 -1.5]"},
+		{"M 0 0 Z m 1 1 l 1 0", "[M 0 0 Z M 1 1 L 2 1]"},
+	}
+	for _, test := range tests {
+		cmds, err := ParsePath(test.d)
+		if err != nil {
+			t.Errorf("ParsePath(%q): %v", test.d, err)
+			continue
+		}
+		if got := fmt.Sprint(cmds); got != test.want {
+			t.Errorf("ParsePath(%q) = %s, want %s", test.d, got, test.want)
+		}
+	}
+	for _, d := range []string{"L 10 10", "M 10", "M 0 0 X 1 1", "M 0 0 A 1 1 0 2 0 1 1", "M 0 0 Z 1 1"} {
+		if _, err := ParsePath(d); err == nil {
+			t.Errorf("ParsePath(%q): no error", d)
+		}
+	}
+}
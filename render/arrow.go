@@ -0,0 +1,159 @@
+package render
+
+import (
+	"math"
+
+	"github.com/ajstarks/deck"
+)
+
+const (
+	arrowlength = 5.0 // default arrowhead length, in line widths
+	arrowwidth  = 0.8 // arrowhead width, relative to its length
+)
+
+// arrowsize returns the length of the arrowheads of a line sw wide
+func arrowsize(cw, sw float64, a deck.Arrowhead) float64 {
+	if a.ArrowSize > 0 {
+		return Pct(a.ArrowSize, cw)
+	}
+	return sw * arrowlength
+}
+
+// arrowends reports which ends of a line have arrowheads
+func arrowends(a deck.Arrowhead) (bool, bool) {
+	return a.Arrow == "start" || a.Arrow == "both", a.Arrow == "end" || a.Arrow == "both"
+}
+
+// arrowhead draws an arrowhead of length size at (x,y), pointing away from (fx,fy),
+// and returns where its line should end: within filled heads, so that the end of the line is covered
+func arrowhead(r Renderer, x, y, fx, fy, size float64, kind string, s Style) (float64, float64) {
+	d := math.Hypot(x-fx, y-fy)
+	if d == 0 || size <= 0 {
+		return x, y
+	}
+	ux, uy := (x-fx)/d, (y-fy)/d   // direction of the head
+	bx, by := x-size*ux, y-size*uy // center of the base
+	hw := size * arrowwidth / 2
+	px, py := -uy*hw, ux*hw // half of the base
+	s.Dash = nil
+	switch kind {
+	case "open":
+		r.Polyline([]float64{bx + px, x, bx - px}, []float64{by + py, y, by - py}, s)
+		return x, y
+	case "dot":
+		r.Ellipse(x, y, size/3, size/3, Style{Color: s.Color, Opacity: s.Opacity})
+		return x, y
+	}
+	r.Polygon([]float64{x, bx + px, bx - px}, []float64{y, by + py, by - py}, Style{Color: s.Color, Opacity: s.Opacity})
+	return x - (size/2)*ux, y - (size/2)*uy
+}
+
+// arrowline draws the arrowheads of connected line segments, then the segments, ending within the heads
+func arrowline(r Renderer, cw float64, x, y []float64, a deck.Arrowhead, s Style) {
+	n := len(x)
+	x, y = append([]float64{}, x...), append([]float64{}, y...)
+	start, end := arrowends(a)
+	size := arrowsize(cw, s.Width, a)
+	if start {
+		x[0], y[0] = arrowhead(r, x[0], y[0], x[1], y[1], size, a.ArrowStyle, s)
+	}
+	if end {
+		x[n-1], y[n-1] = arrowhead(r, x[n-1], y[n-1], x[n-2], y[n-2], size, a.ArrowStyle, s)
+	}
+	if n == 2 {
+		r.Line(x[0], y[0], x[1], y[1], s)
+		return
+	}
+	r.Polyline(x, y, s)
+}
+
+// arrowcurve draws the arrowheads of a quadratic Bezier curve, pointing along the curve, then the curve
+func arrowcurve(r Renderer, cw, x1, y1, x2, y2, x3, y3 float64, a deck.Arrowhead, s Style) {
+	start, end := arrowends(a)
+	size := arrowsize(cw, s.Width, a)
+	if start {
+		fx, fy := x2, y2
+		if fx == x1 && fy == y1 {
+			fx, fy = x3, y3
+		}
+		x1, y1 = arrowhead(r, x1, y1, fx, fy, size, a.ArrowStyle, s)
+	}
+	if end {
+		fx, fy := x2, y2
+		if fx == x3 && fy == y3 {
+			fx, fy = x1, y1
+		}
+		x3, y3 = arrowhead(r, x3, y3, fx, fy, size, a.ArrowStyle, s)
+	}
+	r.Curve(x1, y1, x2, y2, x3, y3, s)
+}
+
+// shape is the outline of an element joined by connectors, in canvas units
+type shape struct {
+	x, y, hw, hh float64 // center, half width and half height
+	round        bool    // elliptical, rather than rectangular
+}
+
+// edge returns where the line from the center of a shape toward (x,y) crosses its outline
+func (s shape) edge(x, y float64) (float64, float64) {
+	dx, dy := x-s.x, y-s.y
+	if (dx == 0 && dy == 0) || s.hw <= 0 || s.hh <= 0 {
+		return s.x, s.y
+	}
+	t := math.Min(s.hw/math.Abs(dx), s.hh/math.Abs(dy))
+	if s.round {
+		t = 1 / math.Hypot(dx/s.hw, dy/s.hh)
+	}
+	return s.x + t*dx, s.y + t*dy
+}
+
+// shapes returns the shapes of the identified rects, ellipses and images of a slide
+func shapes(cw, ch float64, slide deck.Slide, shrink bool) map[string]shape {
+	m := map[string]shape{}
+	for _, e := range slide.Rect {
+		if e.ID != "" {
+			x, y, _ := Dimen(cw, ch, e.Xp, e.Yp, 0)
+			w, h := size(cw, ch, e.Dimension)
+			m[e.ID] = shape{x: x, y: y, hw: w / 2, hh: h / 2}
+		}
+	}
+	for _, e := range slide.Ellipse {
+		if e.ID != "" {
+			x, y, _ := Dimen(cw, ch, e.Xp, e.Yp, 0)
+			w, h := size(cw, ch, e.Dimension)
+			m[e.ID] = shape{x: x, y: y, hw: w / 2, hh: h / 2, round: true}
+		}
+	}
+	for _, im := range slide.Image {
+		if im.ID != "" {
+			x, y, _ := Dimen(cw, ch, im.Xp, im.Yp, 0)
+			w, h := imagesize(cw, im, shrink)
+			m[im.ID] = shape{x: x, y: y, hw: w / 2, hh: h / 2}
+		}
+	}
+	// the shapes of the slide's template may also be connected
+	for _, g := range slide.Group {
+		if g.Master {
+			for id, s := range shapes(cw, ch, g.Elements(), shrink) {
+				if _, ok := m[id]; !ok {
+					m[id] = s
+				}
+			}
+		}
+	}
+	return m
+}
+
+// connector draws a line between the edges of two shapes, with an arrowhead at its end by default
+func connector(r Renderer, cw float64, from, to shape, c deck.Connector, s Style) {
+	x1, y1 := from.edge(to.x, to.y)
+	x2, y2 := to.edge(from.x, from.y)
+	if x1 == x2 && y1 == y2 {
+		return
+	}
+	a := c.Arrowhead
+	if a.Arrow == "" {
+		a.Arrow = "end"
+	}
+	arrowline(r, cw, []float64{x1, x2}, []float64{y1, y2}, a, s)
+}
@@ -0,0 +1,122 @@
+package render
+
+import "github.com/ajstarks/deck"
+
+// build returns a slide as shown at a build step: elements of later steps are left out,
+// and the opacity of elements from earlier steps is combined with dim
+func build(s deck.Slide, step int, dim float64) deck.Slide {
+	// shown reports whether an element at step k is shown, dimming its opacity o if k is past
+	shown := func(k int, o *float64) bool {
+		if k > step {
+			return false
+		}
+		if k > 0 && k < step {
+			*o = groupopacity(*o, dim)
+		}
+		return true
+	}
+	var b deck.Slide
+	for _, e := range s.List {
+		if e.Build == 0 {
+			if shown(e.Step, &e.Opacity) {
+				b.List = append(b.List, e)
+			}
+			continue
+		}
+		// once the list appears, its items are shown, then dimmed, in turn
+		if e.Step > step {
+			continue
+		}
+		var li []deck.ListItem
+		for i, item := range e.Li {
+			if item.Opacity == 0 {
+				item.Opacity = e.Opacity
+			}
+			if shown(e.Build+i, &item.Opacity) {
+				li = append(li, item)
+			}
+		}
+		e.Li = li
+		b.List = append(b.List, e)
+	}
+	for _, e := range s.Text {
+		if shown(e.Step, &e.Opacity) {
+			b.Text = append(b.Text, e)
+		}
+	}
+	for _, e := range s.Image {
+		if shown(e.Step, &e.Opacity) {
+			b.Image = append(b.Image, e)
+		}
+	}
+	for _, e := range s.Ellipse {
+		if shown(e.Step, &e.Opacity) {
+			b.Ellipse = append(b.Ellipse, e)
+		}
+	}
+	for _, e := range s.Rect {
+		if shown(e.Step, &e.Opacity) {
+			b.Rect = append(b.Rect, e)
+		}
+	}
+	for _, e := range s.Line {
+		if shown(e.Step, &e.Opacity) {
+			b.Line = append(b.Line, e)
+		}
+	}
+	for _, e := range s.Curve {
+		if shown(e.Step, &e.Opacity) {
+			b.Curve = append(b.Curve, e)
+		}
+	}
+	for _, e := range s.Arc {
+		if shown(e.Step, &e.Opacity) {
+			b.Arc = append(b.Arc, e)
+		}
+	}
+	for _, e := range s.Polygon {
+		if shown(e.Step, &e.Opacity) {
+			b.Polygon = append(b.Polygon, e)
+		}
+	}
+	for _, e := range s.Polyline {
+		if shown(e.Step, &e.Opacity) {
+			b.Polyline = append(b.Polyline, e)
+		}
+	}
+	for _, e := range s.Path {
+		if shown(e.Step, &e.Opacity) {
+			b.Path = append(b.Path, e)
+		}
+	}
+	for _, e := range s.Connector {
+		if shown(e.Step, &e.Opacity) {
+			b.Connector = append(b.Connector, e)
+		}
+	}
+	for _, e := range s.Table {
+		if shown(e.Step, &e.Opacity) {
+			b.Table = append(b.Table, e)
+		}
+	}
+	for _, e := range s.Chart {
+		if shown(e.Step, &e.Opacity) {
+			b.Chart = append(b.Chart, e)
+		}
+	}
+	for _, g := range s.Group {
+		// the elements of a dimmed group are dimmed with it
+		gdim := dim
+		if g.Step > 0 && g.Step < step {
+			gdim = 0
+		}
+		if shown(g.Step, &g.Opacity) {
+			b.Group = append(b.Group, regroup(g, build(g.Elements(), step, gdim)))
+		}
+	}
+	s.List, s.Text, s.Image = b.List, b.Text, b.Image
+	s.Ellipse, s.Rect, s.Line, s.Curve, s.Arc = b.Ellipse, b.Rect, b.Line, b.Curve, b.Arc
+	s.Polygon, s.Polyline, s.Path, s.Connector = b.Polygon, b.Polyline, b.Path, b.Connector
+	s.Table, s.Chart, s.Group = b.Table, b.Chart, b.Group
+	return s
+}
@@ -0,0 +1,327 @@
+package render
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ajstarks/deck"
+)
+
+const (
+	chartsize  = 1.5
+	chartgrid  = "rgb(220,220,220)"
+	charticks  = 5
+	barfill    = 0.8 // fraction of a category covered by its bars
+	piesegment = 2.0 // degrees per segment of a pie wedge
+)
+
+// seriescolors are the default colors of chart series
+var seriescolors = []string{"steelblue", "darkorange", "seagreen", "firebrick", "slateblue", "goldenrod", "teal", "gray"}
+
+// chartdata is the data of a chart: a label for each row,
+// and the values of each named series
+type chartdata struct {
+	labels []string
+	names  []string
+	series [][]float64
+}
+
+// readchart reads chart data from a CSV or TSV file. The first column holds the labels,
+// the others the series; the first row names the series if its values are not numbers.
+func readchart(filename string) (chartdata, error) {
+	var cd chartdata
+	f, err := os.Open(filename)
+	if err != nil {
+		return cd, err
+	}
+	defer f.Close()
+	cr := csv.NewReader(f)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	if strings.EqualFold(filepath.Ext(filename), ".tsv") {
+		cr.Comma = '\t'
+	}
+	records, err := cr.ReadAll()
+	if err != nil {
+		return cd, err
+	}
+	if len(records) > 0 && !numeric(records[0][1:]) {
+		cd.names = records[0][1:]
+		records = records[1:]
+	}
+	for _, rec := range records {
+		for len(cd.series) < len(rec)-1 {
+			cd.series = append(cd.series, make([]float64, len(cd.labels)))
+		}
+		cd.labels = append(cd.labels, rec[0])
+		for i := range cd.series {
+			v := 0.0
+			if i+1 < len(rec) {
+				v, _ = strconv.ParseFloat(strings.TrimSpace(rec[i+1]), 64)
+			}
+			cd.series[i] = append(cd.series[i], v)
+		}
+	}
+	for len(cd.names) < len(cd.series) {
+		cd.names = append(cd.names, fmt.Sprintf("series %d", len(cd.names)+1))
+	}
+	return cd, nil
+}
+
+// numeric determines if all the fields are numbers
+func numeric(fields []string) bool {
+	for _, s := range fields {
+		if _, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// nicescale returns the bounds and step of a scale covering lo to hi
+// in about n round steps (1, 2 or 5 times a power of ten)
+func nicescale(lo, hi float64, n int) (float64, float64, float64) {
+	if hi <= lo {
+		hi = lo + 1
+	}
+	x := (hi - lo) / float64(n)
+	e := math.Pow(10, math.Floor(math.Log10(x)))
+	var step float64
+	switch f := x / e; {
+	case f <= 1:
+		step = e
+	case f <= 2:
+		step = 2 * e
+	case f <= 5:
+		step = 5 * e
+	default:
+		step = 10 * e
+	}
+	return math.Floor(lo/step) * step, math.Ceil(hi/step) * step, step
+}
+
+// ticklabel formats the value of a tick to the precision of the step
+func ticklabel(v, step float64) string {
+	decimals := 0
+	if step < 1 {
+		decimals = int(math.Ceil(-math.Log10(step)))
+	}
+	return strconv.FormatFloat(v, 'f', decimals, 64)
+}
+
+// vmap maps a value from the range low1-high1 to low2-high2
+func vmap(v, low1, high1, low2, high2 float64) float64 {
+	return low2 + (high2-low2)*(v-low1)/(high1-low1)
+}
+
+// limits returns the lowest and highest values of the series, including zero
+func limits(series [][]float64) (float64, float64) {
+	lo, hi := 0.0, 0.0
+	for _, s := range series {
+		for _, v := range s {
+			lo = math.Min(lo, v)
+			hi = math.Max(hi, v)
+		}
+	}
+	return lo, hi
+}
+
+// chart draws a chart with the lower left corner of its plot at (x,y),
+// its plot w wide and h high, labeled with text of size fs
+func chart(r Renderer, x, y, w, h, fs float64, c deck.Chart) {
+	cd, err := readchart(c.Data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return
+	}
+	if len(cd.labels) == 0 || len(cd.series) == 0 {
+		return
+	}
+	colors := deck.ColorList(c.Colors)
+	for i := len(colors); i < len(cd.series); i++ {
+		colors = append(colors, seriescolors[i%len(seriescolors)])
+	}
+	ls := Style{Color: c.Color, Opacity: c.Opacity, Font: c.Font, Size: fs}
+	if c.Rotation > 0 {
+		r.Rotate(x, y, c.Rotation)
+	}
+	if c.Type == "pie" {
+		pie(r, x, y, w, h, fs, cd, colors, ls, c.Legend != "off")
+	} else {
+		plot(r, x, y, w, h, fs, cd, colors, ls, c)
+	}
+	if c.Rotation > 0 {
+		r.EndRotate()
+	}
+}
+
+// plot draws the axes, gridlines, labels and series of bar, line and scatter charts
+func plot(r Renderer, x, y, w, h, fs float64, cd chartdata, colors []string, ls Style, c deck.Chart) {
+	lo, hi := limits(cd.series)
+	lo, hi, step := nicescale(lo, hi, charticks)
+	if c.Min != 0 {
+		lo = c.Min
+	}
+	if c.Max != 0 {
+		hi = c.Max
+	}
+	if hi <= lo {
+		return
+	}
+	yv := func(v float64) float64 { return vmap(v, lo, hi, y, y-h) }
+
+	// value axis, with gridlines and labels
+	gs := Style{Color: chartgrid, Width: fs / 10}
+	vs := ls
+	vs.Align = "end"
+	for v := math.Ceil(lo/step) * step; v <= hi+step/1e6; v += step {
+		ty := yv(v)
+		if c.Grid != "off" {
+			r.Line(x, ty, x+w, ty, gs)
+		}
+		r.Text(x-(fs/2), ty+(fs/3), ticklabel(v, step), vs)
+	}
+
+	// the x position of each row: by value for scatter charts, by category otherwise
+	n := len(cd.labels)
+	cat := w / float64(n)
+	xs := make([]float64, n)
+	for i := range xs {
+		xs[i] = x + (cat * (float64(i) + 0.5))
+	}
+	cs := ls
+	cs.Align = "center"
+	if xvals, ok := values(cd.labels); c.Type == "scatter" && ok {
+		xlo, xhi := xvals[0], xvals[0]
+		for _, v := range xvals {
+			xlo, xhi = math.Min(xlo, v), math.Max(xhi, v)
+		}
+		xlo, xhi, xstep := nicescale(xlo, xhi, charticks)
+		for i, v := range xvals {
+			xs[i] = vmap(v, xlo, xhi, x, x+w)
+		}
+		for v := xlo; v <= xhi+xstep/1e6; v += xstep {
+			tx := vmap(v, xlo, xhi, x, x+w)
+			if c.Grid != "off" {
+				r.Line(tx, y, tx, y-h, gs)
+			}
+			r.Text(tx, y+(fs*1.5), ticklabel(v, xstep), cs)
+		}
+	} else {
+		for i, label := range cd.labels {
+			r.Text(xs[i], y+(fs*1.5), label, cs)
+		}
+	}
+
+	// series
+	zero := yv(math.Max(lo, math.Min(hi, 0)))
+	bw := (cat * barfill) / float64(len(cd.series))
+	for s, values := range cd.series {
+		st := fill(colors[s], ls.Opacity)
+		switch c.Type {
+		case "line":
+			py := make([]float64, n)
+			for i, v := range values {
+				py[i] = yv(v)
+				r.Ellipse(xs[i], py[i], fs/4, fs/4, st)
+			}
+			if n > 1 {
+				r.Polyline(xs, py, stroke(fs/5, colors[s], ls.Opacity))
+			}
+		case "scatter":
+			for i, v := range values {
+				r.Ellipse(xs[i], yv(v), fs/3, fs/3, st)
+			}
+		default:
+			for i, v := range values {
+				bx := xs[i] - (cat * barfill / 2) + (bw * float64(s))
+				top, bottom := yv(v), zero
+				if top > bottom {
+					top, bottom = bottom, top
+				}
+				r.Rect(bx, top, bw, bottom-top, st)
+			}
+		}
+	}
+
+	// axes
+	as := Style{Color: ls.Color, Opacity: ls.Opacity, Width: fs / 8}
+	r.Line(x, y, x, y-h, as)
+	r.Line(x, zero, x+w, zero, as)
+
+	if c.Legend != "off" && len(cd.series) > 1 {
+		legend(r, x+w+fs, y-h, fs, cd.names, colors, ls)
+	}
+}
+
+// values converts labels to numbers, reporting if all are numbers
+func values(labels []string) ([]float64, bool) {
+	v := make([]float64, len(labels))
+	for i, s := range labels {
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return nil, false
+		}
+		v[i] = f
+	}
+	return v, true
+}
+
+// pie draws the first series as wedges of a circle fitting the plot, colored by row,
+// with a legend of the labels and their percentages
+func pie(r Renderer, x, y, w, h, fs float64, cd chartdata, colors []string, ls Style, showlegend bool) {
+	total := 0.0
+	for _, v := range cd.series[0] {
+		if v > 0 {
+			total += v
+		}
+	}
+	if total == 0 {
+		return
+	}
+	rad := math.Min(w, h) / 2
+	cx, cy := x+rad, y-rad
+	if len(colors) < len(cd.labels) {
+		for i := len(colors); i < len(cd.labels); i++ {
+			colors = append(colors, seriescolors[i%len(seriescolors)])
+		}
+	}
+	names := make([]string, len(cd.labels))
+	a1 := 90.0 // start at the top, going clockwise
+	for i, v := range cd.series[0] {
+		names[i] = cd.labels[i]
+		if v <= 0 {
+			continue
+		}
+		names[i] = fmt.Sprintf("%s (%.0f%%)", cd.labels[i], 100*v/total)
+		a2 := a1 - (360 * v / total)
+		px, py := []float64{cx}, []float64{cy}
+		for a := a1; a > a2; a -= piesegment {
+			px = append(px, cx+rad*math.Cos(a*math.Pi/180))
+			py = append(py, cy-rad*math.Sin(a*math.Pi/180))
+		}
+		px = append(px, cx+rad*math.Cos(a2*math.Pi/180))
+		py = append(py, cy-rad*math.Sin(a2*math.Pi/180))
+		r.Polygon(px, py, fill(colors[i], ls.Opacity))
+		a1 = a2
+	}
+	if showlegend {
+		legend(r, cx+rad+(fs*2), y-(2*rad), fs, names, colors, ls)
+	}
+}
+
+// legend draws a color key for each name, from (x,y) downward
+func legend(r Renderer, x, y, fs float64, names, colors []string, ls Style) {
+	ls.Align = "begin"
+	for i, name := range names {
+		r.Rect(x, y, fs, fs, fill(colors[i], ls.Opacity))
+		r.Text(x+(fs*1.5), y+fs, name, ls)
+		y += fs * 1.8
+	}
+}
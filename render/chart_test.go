@@ -0,0 +1,75 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ajstarks/deck"
+)
+
+func TestReadchart(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name, data string
+		want       chartdata
+	}{
+		{"h.csv", "# sales\nquarter,north,south\nQ1,1,2\nQ2,3,4\n",
+			chartdata{labels: []string{"Q1", "Q2"}, names: []string{"north", "south"}, series: [][]float64{{1, 3}, {2, 4}}}},
+		{"n.tsv", "1\t2.5\n2\t3\n",
+			chartdata{labels: []string{"1", "2"}, names: []string{"series 1"}, series: [][]float64{{2.5, 3}}}},
+	}
+	for _, test := range tests {
+		file := filepath.Join(dir, test.name)
+		if err := os.WriteFile(file, []byte(test.data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cd, err := readchart(file)
+		if err != nil || !reflect.DeepEqual(cd, test.want) {
+			t.Errorf("%s: got %+v, %v want %+v", test.name, cd, err, test.want)
+		}
+	}
+}
+
+func TestNicescale(t *testing.T) {
+	tests := []struct{ lo, hi, wlo, whi, wstep float64 }{
+		{0, 20, 0, 20, 5},
+		{0, 173, 0, 200, 50},
+		{-3, 7, -4, 8, 2},
+		{0, 0.42, 0, 0.5, 0.1},
+	}
+	for _, tc := range tests {
+		lo, hi, step := nicescale(tc.lo, tc.hi, 5)
+		if lo != tc.wlo || hi != tc.whi || step != tc.wstep {
+			t.Errorf("nicescale(%v, %v): got %v %v %v, want %v %v %v", tc.lo, tc.hi, lo, hi, step, tc.wlo, tc.whi, tc.wstep)
+		}
+	}
+}
+
+func TestBarChart(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(file, []byte("label,value\nx,10\ny,20\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var s deck.Slide
+	c := deck.Chart{Data: file, Wp: 80, Hp: 80}
+	c.Xp, c.Yp, c.Sp = 10, 10, 2
+	s.Chart = []deck.Chart{c}
+	r := &recorder{}
+	Slide(r, testdeck(s), 0, Options{Layers: "chart"})
+	ops := strings.Join(r.ops, "\n")
+	for _, want := range []string{
+		"line 100 50 900 50 2 rgb(220,220,220)",
+		`text 90 57 "20" sans black`,
+		`text 300 480 "x" sans black`,
+		"rect 140 250 320 200 steelblue",
+		"rect 540 50 320 400 steelblue",
+		"line 100 450 100 50 2 black",
+	} {
+		if !strings.Contains(ops, want) {
+			t.Errorf("missing %q in\n%s", want, ops)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package render
+
+import (
+	"math"
+
+	"github.com/ajstarks/deck"
+)
+
+// Gradient is a gradient fill, in canvas units: linear from Color1 at (X1,Y1) to Color2 at (X2,Y2),
+// or radial from Color1 at the center (X1,Y1) to Color2 at radius R.
+// Beyond its ends, a gradient continues in its end colors.
+type Gradient struct {
+	Color1, Color2 string
+	Radial         bool
+	X1, Y1, X2, Y2 float64
+	R              float64
+}
+
+// gradient returns the gradient filling the rectangle with its upper left corner at (x,y),
+// or nil unless both gradient colors are specified. Linear gradients run across the rectangle
+// from top to bottom, turned counterclockwise by the gradient angle;
+// radial gradients reach the farther sides of the rectangle.
+func gradient(g deck.Gradient, x, y, w, h float64) *Gradient {
+	if g.Gradcolor1 == "" || g.Gradcolor2 == "" {
+		return nil
+	}
+	gp := g.GradPercent
+	if gp <= 0 || gp > 100 {
+		gp = 100
+	}
+	f := &Gradient{Color1: g.Gradcolor1, Color2: g.Gradcolor2, X1: x + w/2, Y1: y + h/2}
+	if g.GradType == "radial" {
+		f.Radial = true
+		f.X2, f.Y2 = f.X1, f.Y1
+		f.R = math.Max(w, h) / 2 * gp / 100
+		return f
+	}
+	// the direction of the gradient, and half its length across the rectangle
+	dx, dy := math.Sincos(g.GradAngle * math.Pi / 180)
+	e := math.Abs(dx)*w/2 + math.Abs(dy)*h/2
+	f.X1, f.Y1 = f.X1-e*dx, f.Y1-e*dy
+	f.X2, f.Y2 = f.X1+2*e*dx*gp/100, f.Y1+2*e*dy*gp/100
+	return f
+}
+
+// gradientfill fills a shape style with the gradient across the shape's bounds, unless the shape is unfilled
+func gradientfill(s Style, g deck.Gradient, x, y, w, h float64) Style {
+	if !s.NoFill {
+		s.Gradient = gradient(g, x, y, w, h)
+	}
+	return s
+}
+
+// bounds returns the upper left corner, width and height of the rectangle enclosing a set of points
+func bounds(x, y []float64) (float64, float64, float64, float64) {
+	if len(x) == 0 {
+		return 0, 0, 0, 0
+	}
+	minx, maxx, miny, maxy := x[0], x[0], y[0], y[0]
+	for i := range x {
+		minx, maxx = math.Min(minx, x[i]), math.Max(maxx, x[i])
+		miny, maxy = math.Min(miny, y[i]), math.Max(maxy, y[i])
+	}
+	return minx, miny, maxx - minx, maxy - miny
+}
+
+// pathbounds returns the rectangle enclosing a path
+func pathbounds(ops []PathOp) (float64, float64, float64, float64) {
+	var x, y []float64
+	px, py := Subpaths(ops)
+	for i := range px {
+		x, y = append(x, px[i]...), append(y, py[i]...)
+	}
+	return bounds(x, y)
+}
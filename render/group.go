@@ -0,0 +1,271 @@
+package render
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ajstarks/deck"
+)
+
+// transform maps the percentage coordinates and sizes of grouped elements
+// to those of the slide: scaling by s about (ax, ay), then moving by (tx, ty)
+type transform struct {
+	s, ax, ay, tx, ty float64
+}
+
+func (t transform) x(v float64) float64 { return t.ax + t.s*(v-t.ax) + t.tx }
+func (t transform) y(v float64) float64 { return t.ay + t.s*(v-t.ay) + t.ty }
+
+// coords maps a string of space-separated coordinates
+func (t transform) coords(c string, f func(float64) float64) string {
+	fields := strings.Fields(c)
+	for i, s := range fields {
+		if v, err := strconv.ParseFloat(s, 64); err == nil {
+			fields[i] = strconv.FormatFloat(f(v), 'f', -1, 64)
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// sizes scales a string of space-separated sizes
+func (t transform) sizes(c string) string {
+	return t.coords(c, func(v float64) float64 { return v * t.s })
+}
+
+// path maps path data, scaling the radii of arcs; malformed data is left to be reported when drawn
+func (t transform) path(d string) string {
+	cmds, err := deck.ParsePath(d)
+	if err != nil {
+		return d
+	}
+	s := make([]string, len(cmds))
+	for i, c := range cmds {
+		args := append([]float64{}, c.Args...)
+		if c.Cmd == 'A' {
+			args[0], args[1], args[5], args[6] = args[0]*t.s, args[1]*t.s, t.x(args[5]), t.y(args[6])
+		} else {
+			for j := 0; j < len(args); j += 2 {
+				args[j], args[j+1] = t.x(args[j]), t.y(args[j+1])
+			}
+		}
+		s[i] = deck.PathCmd{Cmd: c.Cmd, Args: args}.String()
+	}
+	return strings.Join(s, " ")
+}
+
+func (t transform) common(c *deck.CommonAttr) {
+	c.Xp, c.Yp, c.Sp = t.x(c.Xp), t.y(c.Yp), c.Sp*t.s
+}
+
+func (t transform) dimension(d *deck.Dimension) {
+	t.common(&d.CommonAttr)
+	d.Wp, d.Hp = d.Wp*t.s, d.Hp*t.s
+}
+
+// slide returns a copy of the elements of a slide, with coordinates and sizes transformed;
+// the elements of groups are transformed along with their centers and translations
+func (t transform) slide(s deck.Slide) deck.Slide {
+	var ts deck.Slide
+	for _, e := range s.List {
+		t.common(&e.CommonAttr)
+		e.Wp *= t.s
+		ts.List = append(ts.List, e)
+	}
+	for _, e := range s.Text {
+		t.common(&e.CommonAttr)
+		e.Wp *= t.s
+		ts.Text = append(ts.Text, e)
+	}
+	for _, e := range s.Image {
+		t.common(&e.CommonAttr)
+		if e.Scale == 0 {
+			e.Scale = 100
+		}
+		e.Scale *= t.s
+		ts.Image = append(ts.Image, e)
+	}
+	for _, e := range s.Ellipse {
+		t.dimension(&e.Dimension)
+		e.StrokeWidth *= t.s
+		ts.Ellipse = append(ts.Ellipse, e)
+	}
+	for _, e := range s.Rect {
+		t.dimension(&e.Dimension)
+		e.StrokeWidth *= t.s
+		ts.Rect = append(ts.Rect, e)
+	}
+	for _, e := range s.Line {
+		e.Xp1, e.Yp1, e.Xp2, e.Yp2, e.Sp = t.x(e.Xp1), t.y(e.Yp1), t.x(e.Xp2), t.y(e.Yp2), e.Sp*t.s
+		e.ArrowSize *= t.s
+		ts.Line = append(ts.Line, e)
+	}
+	for _, e := range s.Curve {
+		e.Xp1, e.Yp1, e.Xp2, e.Yp2, e.Sp = t.x(e.Xp1), t.y(e.Yp1), t.x(e.Xp2), t.y(e.Yp2), e.Sp*t.s
+		e.Xp3, e.Yp3, e.ArrowSize = t.x(e.Xp3), t.y(e.Yp3), e.ArrowSize*t.s
+		ts.Curve = append(ts.Curve, e)
+	}
+	for _, e := range s.Arc {
+		t.dimension(&e.Dimension)
+		e.Sp *= t.s
+		ts.Arc = append(ts.Arc, e)
+	}
+	for _, e := range s.Polygon {
+		e.XC, e.YC, e.StrokeWidth = t.coords(e.XC, t.x), t.coords(e.YC, t.y), e.StrokeWidth*t.s
+		ts.Polygon = append(ts.Polygon, e)
+	}
+	for _, e := range s.Polyline {
+		e.XC, e.YC, e.Sp = t.coords(e.XC, t.x), t.coords(e.YC, t.y), e.Sp*t.s
+		e.ArrowSize *= t.s
+		ts.Polyline = append(ts.Polyline, e)
+	}
+	for _, e := range s.Path {
+		e.D, e.StrokeWidth = t.path(e.D), e.StrokeWidth*t.s
+		ts.Path = append(ts.Path, e)
+	}
+	for _, e := range s.Connector {
+		e.Sp, e.ArrowSize = e.Sp*t.s, e.ArrowSize*t.s
+		ts.Connector = append(ts.Connector, e)
+	}
+	for _, e := range s.Table {
+		if e.Sp == 0 {
+			e.Sp = tablesize
+		}
+		t.common(&e.CommonAttr)
+		e.Wp *= t.s
+		e.Widths = t.sizes(e.Widths)
+		ts.Table = append(ts.Table, e)
+	}
+	for _, e := range s.Chart {
+		if e.Sp == 0 {
+			e.Sp = chartsize
+		}
+		t.common(&e.CommonAttr)
+		e.Wp, e.Hp = e.Wp*t.s, e.Hp*t.s
+		ts.Chart = append(ts.Chart, e)
+	}
+	for _, g := range s.Group {
+		g.Xp, g.Yp, g.Tx, g.Ty = t.x(g.Xp), t.y(g.Yp), g.Tx*t.s, g.Ty*t.s
+		ts.Group = append(ts.Group, regroup(g, t.slide(g.Elements())))
+	}
+	return ts
+}
+
+// regroup returns a group with the elements of s
+func regroup(g deck.Group, s deck.Slide) deck.Group {
+	g.List, g.Text, g.Image = s.List, s.Text, s.Image
+	g.Ellipse, g.Rect, g.Line, g.Curve, g.Arc = s.Ellipse, s.Rect, s.Line, s.Curve, s.Arc
+	g.Polygon, g.Polyline, g.Path, g.Connector = s.Polygon, s.Polyline, s.Path, s.Connector
+	g.Table, g.Chart, g.Group = s.Table, s.Chart, s.Group
+	return g
+}
+
+// groupopacity combines the opacity of an element with that of its group
+func groupopacity(e, g float64) float64 {
+	switch {
+	case g == 0:
+		return e
+	case e < 0 || g < 0:
+		return -1
+	case e == 0:
+		return g
+	}
+	return e * g / 100
+}
+
+// inherit applies the color, font and opacity of a group to its elements
+func inherit(s *deck.Slide, g deck.Group) {
+	color := func(c *string) {
+		if *c == "" {
+			*c = g.Color
+		}
+	}
+	font := func(f *string) {
+		if *f == "" {
+			*f = g.Font
+		}
+	}
+	for i := range s.List {
+		font(&s.List[i].Font)
+		s.List[i].Opacity = groupopacity(s.List[i].Opacity, g.Opacity)
+	}
+	for i := range s.Text {
+		font(&s.Text[i].Font)
+		s.Text[i].Opacity = groupopacity(s.Text[i].Opacity, g.Opacity)
+	}
+	for i := range s.Image {
+		font(&s.Image[i].Font)
+		s.Image[i].Opacity = groupopacity(s.Image[i].Opacity, g.Opacity)
+	}
+	for i := range s.Ellipse {
+		color(&s.Ellipse[i].Color)
+		s.Ellipse[i].Opacity = groupopacity(s.Ellipse[i].Opacity, g.Opacity)
+	}
+	for i := range s.Rect {
+		color(&s.Rect[i].Color)
+		s.Rect[i].Opacity = groupopacity(s.Rect[i].Opacity, g.Opacity)
+	}
+	for i := range s.Line {
+		color(&s.Line[i].Color)
+		s.Line[i].Opacity = groupopacity(s.Line[i].Opacity, g.Opacity)
+	}
+	for i := range s.Curve {
+		color(&s.Curve[i].Color)
+		s.Curve[i].Opacity = groupopacity(s.Curve[i].Opacity, g.Opacity)
+	}
+	for i := range s.Arc {
+		color(&s.Arc[i].Color)
+		s.Arc[i].Opacity = groupopacity(s.Arc[i].Opacity, g.Opacity)
+	}
+	for i := range s.Polygon {
+		color(&s.Polygon[i].Color)
+		s.Polygon[i].Opacity = groupopacity(s.Polygon[i].Opacity, g.Opacity)
+	}
+	for i := range s.Polyline {
+		color(&s.Polyline[i].Color)
+		s.Polyline[i].Opacity = groupopacity(s.Polyline[i].Opacity, g.Opacity)
+	}
+	for i := range s.Path {
+		color(&s.Path[i].Color)
+		s.Path[i].Opacity = groupopacity(s.Path[i].Opacity, g.Opacity)
+	}
+	for i := range s.Connector {
+		color(&s.Connector[i].Color)
+		s.Connector[i].Opacity = groupopacity(s.Connector[i].Opacity, g.Opacity)
+	}
+	for i := range s.Table {
+		font(&s.Table[i].Font)
+		s.Table[i].Opacity = groupopacity(s.Table[i].Opacity, g.Opacity)
+	}
+	for i := range s.Chart {
+		font(&s.Chart[i].Font)
+		s.Chart[i].Opacity = groupopacity(s.Chart[i].Opacity, g.Opacity)
+	}
+	for i := range s.Group {
+		color(&s.Group[i].Color)
+		font(&s.Group[i].Font)
+		s.Group[i].Opacity = groupopacity(s.Group[i].Opacity, g.Opacity)
+	}
+}
+
+// group draws the elements of a group, transformed, and rotated about its moved center;
+// fg is the foreground color of the enclosing slide or group
+func group(r Renderer, d deck.Deck, n int, fg string, g deck.Group, o Options) {
+	t := transform{s: 1, ax: g.Xp, ay: g.Yp, tx: g.Tx, ty: g.Ty}
+	if g.Scale > 0 {
+		t.s = g.Scale / 100
+	}
+	s := t.slide(g.Elements())
+	inherit(&s, g)
+	s.Fg = fg
+	if g.Color != "" {
+		s.Fg = g.Color
+	}
+	if g.Rotation != 0 {
+		x, y, _ := Dimen(float64(d.Canvas.Width), float64(d.Canvas.Height), t.x(g.Xp), t.y(g.Yp), 0)
+		r.Rotate(x, y, g.Rotation)
+	}
+	elements(r, d, n, s, o)
+	if g.Rotation != 0 {
+		r.EndRotate()
+	}
+}
@@ -0,0 +1,233 @@
+package render
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	mdheading = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+	mditem    = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	mdfence   = regexp.MustCompile("^\\s*(```|~~~)")
+)
+
+// inline parses Markdown inline markup: **bold**, __bold__, *emphasis*,
+// _emphasis_, `code` and [links](url), returning styled spans.
+func inline(s string, st Style) []span {
+	var spans []span
+	var b strings.Builder
+	cur := st
+	emit := func() {
+		if b.Len() > 0 {
+			spans = append(spans, span{text: b.String(), st: cur})
+			b.Reset()
+		}
+	}
+	code := func(on bool) {
+		if on {
+			cur.Font = "mono"
+		} else {
+			cur.Font = st.Font
+		}
+	}
+	incode := false
+	rs := []rune(s)
+	for i := 0; i < len(rs); i++ {
+		c := rs[i]
+		if incode {
+			if c == '`' {
+				emit()
+				incode = false
+				code(false)
+				continue
+			}
+			b.WriteRune(c)
+			continue
+		}
+		switch {
+		case c == '\\' && i+1 < len(rs) && unicode.IsPunct(rs[i+1]):
+			i++
+			b.WriteRune(rs[i])
+		case c == '`':
+			emit()
+			incode = true
+			code(true)
+		case (c == '*' || c == '_') && i+1 < len(rs) && rs[i+1] == c:
+			emit()
+			cur.Bold = !cur.Bold
+			i++
+		case c == '*' || (c == '_' && emphasis(rs, i, cur.Italic)):
+			emit()
+			cur.Italic = !cur.Italic
+		case c == '[':
+			text, url, n := mdlink(rs[i:])
+			if n == 0 {
+				b.WriteRune(c)
+				continue
+			}
+			emit()
+			ls := cur
+			ls.Link = url
+			spans = append(spans, inline(text, ls)...)
+			i += n - 1
+		default:
+			b.WriteRune(c)
+		}
+	}
+	emit()
+	return spans
+}
+
+// emphasis determines if the underscore at rs[i] opens or closes emphasis,
+// rather than appearing within a word (snake_case)
+func emphasis(rs []rune, i int, open bool) bool {
+	if open {
+		return i+1 == len(rs) || !isword(rs[i+1])
+	}
+	return i == 0 || !isword(rs[i-1])
+}
+
+func isword(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// mdlink parses a link [text](url) at the start of rs,
+// returning the text, url and number of runes consumed (0 if not a link)
+func mdlink(rs []rune) (string, string, int) {
+	s := string(rs)
+	close := strings.Index(s, "](")
+	if close < 0 {
+		return "", "", 0
+	}
+	end := strings.IndexByte(s[close:], ')')
+	if end < 0 {
+		return "", "", 0
+	}
+	end += close
+	return s[1:close], strings.TrimSpace(s[close+2 : end]), len([]rune(s[:end+1]))
+}
+
+// markdown draws Markdown text within a box of width w starting at (x,y):
+// paragraphs, headings, nested bullet and numbered lists, and fenced code,
+// with inline emphasis, bold, code and links.
+func markdown(r Renderer, x, y, w, leading float64, s string, st Style) {
+	lines := dedent(strings.Split(strings.ReplaceAll(s, "\t", "    "), "\n"))
+	size := st.Size
+	var para []string
+	var item []string
+	var indents []int
+	var marker string
+	var level int
+	gap := true // no space before the first block
+
+	// advance moves down after a block of n+1 lines
+	advance := func(n int, lead float64) {
+		y += lead * float64(n+1)
+		gap = false
+	}
+	drawitem := func() {
+		if len(item) == 0 {
+			return
+		}
+		ix := x + float64(level)*size*1.5
+		tx := ix + size*1.2
+		if marker == "-" || marker == "*" || marker == "+" {
+			rs := size / 4
+			r.Ellipse(ix+rs, y-rs, rs, rs, Style{Color: st.Color, Opacity: st.Opacity})
+		} else {
+			ms := st
+			ms.Align = ""
+			r.Text(ix, y, marker, ms)
+			if mw := r.TextWidth(marker+" ", ms); ix+mw > tx {
+				tx = ix + mw
+			}
+		}
+		advance(flow(r, tx, y, w-(tx-x), leading, inline(strings.Join(item, " "), st)), leading)
+		item = nil
+	}
+	flush := func() {
+		if len(para) > 0 {
+			advance(flow(r, x, y, w, leading, inline(strings.Join(para, " "), st)), leading)
+			para = nil
+		}
+		drawitem()
+	}
+	space := func() {
+		if !gap {
+			y += leading * 0.5
+			gap = true
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		l := lines[i]
+		t := strings.TrimSpace(l)
+		switch m := mditem.FindStringSubmatch(l); {
+		case t == "":
+			flush()
+			indents = nil
+			space()
+		case mdfence.MatchString(l):
+			flush()
+			fence := mdfence.FindStringSubmatch(l)[1]
+			cs := st
+			cs.Font, cs.Align = "mono", ""
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+				r.Text(x, y, lines[i], cs)
+				y += leading
+			}
+			gap = false
+		case mdheading.MatchString(t):
+			flush()
+			m := mdheading.FindStringSubmatch(t)
+			hs := st
+			hs.Bold = true
+			if n := len(m[1]); n < 4 {
+				hs.Size = size * (1 + float64(4-n)*0.25)
+			}
+			lead := leading * hs.Size / size
+			advance(flow(r, x, y, w, lead, inline(m[2], hs)), lead)
+		case m != nil:
+			flush()
+			indent := len(m[1])
+			for len(indents) > 0 && indents[len(indents)-1] > indent {
+				indents = indents[:len(indents)-1]
+			}
+			if len(indents) == 0 || indents[len(indents)-1] < indent {
+				indents = append(indents, indent)
+			}
+			level = len(indents) - 1
+			marker, item = m[2], []string{m[3]}
+		case len(item) > 0:
+			item = append(item, t) // continuation of a list item
+		default:
+			para = append(para, t)
+		}
+	}
+	flush()
+}
+
+// dedent removes the indentation common to all non-blank lines
+func dedent(lines []string) []string {
+	min := -1
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		n := len(l) - len(strings.TrimLeft(l, " "))
+		if min < 0 || n < min {
+			min = n
+		}
+	}
+	if min <= 0 {
+		return lines
+	}
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		if len(l) >= min {
+			out[i] = l[min:]
+		}
+	}
+	return out
+}
@@ -0,0 +1,74 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestInline(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain text", `"plain text"`},
+		{"a **bold** b", `"a " "bold"B " b"`},
+		{"*em* and __strong__", `"em"I " and " "strong"B`},
+		{"a `x*y` b", `"a " "x*y"mono " b"`},
+		{"see [the *docs*](http://x.org) now", `"see " "the "@http://x.org "docs"I@http://x.org " now"`},
+		{"snake_case_name", `"snake_case_name"`},
+		{`\*literal\*`, `"*literal*"`},
+		{"[not a link]", `"[not a link]"`},
+	}
+	for _, test := range tests {
+		var got []string
+		for _, sp := range inline(test.in, Style{Font: "sans"}) {
+			s := fmt.Sprintf("%q", sp.text)
+			if sp.st.Bold {
+				s += "B"
+			}
+			if sp.st.Italic {
+				s += "I"
+			}
+			if sp.st.Font != "sans" {
+				s += sp.st.Font
+			}
+			if sp.st.Link != "" {
+				s += "@" + sp.st.Link
+			}
+			got = append(got, s)
+		}
+		if g := strings.Join(got, " "); g != test.want {
+			t.Errorf("inline(%q) = %s, want %s", test.in, g, test.want)
+		}
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	r := &recorder{}
+	md := `
+	Some **bold** text
+	* one
+	  1. two
+	* three
+
+	` + "```" + `
+	x := 1
+	` + "```"
+	markdown(r, 0, 10, 100, 10, md, Style{Font: "sans", Size: 10})
+	want := []string{
+		`text 0 10 "Some" sans `,
+		`text 22 10 "bold" sans `,
+		`text 43 10 "text" sans `,
+		`ellipse 2 18 2 2 `,
+		`text 12 20 "one" sans `,
+		`text 15 30 "1." sans `,
+		`text 30 30 "two" sans `,
+		`ellipse 2 38 2 2 `,
+		`text 12 40 "three" sans `,
+		`text 0 55 "x := 1" mono `,
+	}
+	if strings.Join(r.ops, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(r.ops, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,148 @@
+package render
+
+import (
+	"math"
+
+	"github.com/ajstarks/deck"
+)
+
+// PathOp is an operation of a path, in canvas coordinates: M (move), L (line),
+// Q (quadratic Bezier), C (cubic Bezier) or Z (close). X and Y hold the control points
+// of the operation followed by its end point.
+type PathOp struct {
+	Op   byte
+	X, Y []float64
+}
+
+// pathops converts path commands to canvas operations, with arcs made of cubic Beziers.
+// Arcs turn counterclockwise with sweep 1 and rotate counterclockwise, since y increases upward in deck coordinates.
+func pathops(cw, ch float64, cmds []deck.PathCmd) []PathOp {
+	var ops []PathOp
+	var cx, cy float64
+	for _, c := range cmds {
+		if c.Cmd == 'Z' {
+			ops = append(ops, PathOp{Op: 'Z'})
+			continue
+		}
+		n := len(c.Args)
+		x, y, _ := Dimen(cw, ch, c.Args[n-2], c.Args[n-1], 0)
+		if c.Cmd == 'A' {
+			rx, ry := Pct(c.Args[0], cw), Pct(c.Args[1], cw)
+			ops = append(ops, arcops(cx, cy, rx, ry, -c.Args[2], c.Args[3] != 0, c.Args[4] == 0, x, y)...)
+			cx, cy = x, y
+			continue
+		}
+		op := PathOp{Op: c.Cmd}
+		for i := 0; i < n; i += 2 {
+			px, py, _ := Dimen(cw, ch, c.Args[i], c.Args[i+1], 0)
+			op.X, op.Y = append(op.X, px), append(op.Y, py)
+		}
+		ops = append(ops, op)
+		cx, cy = x, y
+	}
+	// the current point after a close is the start of the subpath
+	for i, sx, sy := 0, 0.0, 0.0; i < len(ops); i++ {
+		if ops[i].Op == 'M' {
+			sx, sy = ops[i].X[0], ops[i].Y[0]
+		}
+		if ops[i].Op == 'Z' && i+1 < len(ops) && ops[i+1].Op != 'M' {
+			ops = append(ops[:i+1], append([]PathOp{{Op: 'M', X: []float64{sx}, Y: []float64{sy}}}, ops[i+1:]...)...)
+		}
+	}
+	return ops
+}
+
+// arcops approximates the elliptical arc from (x1,y1) to (x2,y2), with radii (rx,ry)
+// rotated by phi degrees, with cubic Beziers (SVG implementation notes, F.6)
+func arcops(x1, y1, rx, ry, phi float64, large, sweep bool, x2, y2 float64) []PathOp {
+	if x1 == x2 && y1 == y2 {
+		return nil
+	}
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		return []PathOp{{Op: 'L', X: []float64{x2}, Y: []float64{y2}}}
+	}
+	sinp, cosp := math.Sincos(phi * math.Pi / 180)
+	dx, dy := (x1-x2)/2, (y1-y2)/2
+	xp, yp := cosp*dx+sinp*dy, -sinp*dx+cosp*dy
+	if l := (xp*xp)/(rx*rx) + (yp*yp)/(ry*ry); l > 1 {
+		rx, ry = rx*math.Sqrt(l), ry*math.Sqrt(l)
+	}
+	num := rx*rx*ry*ry - rx*rx*yp*yp - ry*ry*xp*xp
+	den := rx*rx*yp*yp + ry*ry*xp*xp
+	co := 0.0
+	if num > 0 && den > 0 {
+		co = math.Sqrt(num / den)
+	}
+	if large == sweep {
+		co = -co
+	}
+	cxp, cyp := co*rx*yp/ry, -co*ry*xp/rx
+	cx := cosp*cxp - sinp*cyp + (x1+x2)/2
+	cy := sinp*cxp + cosp*cyp + (y1+y2)/2
+
+	angle := func(ux, uy, vx, vy float64) float64 { return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy) }
+	t1 := angle(1, 0, (xp-cxp)/rx, (yp-cyp)/ry)
+	dt := angle((xp-cxp)/rx, (yp-cyp)/ry, (-xp-cxp)/rx, (-yp-cyp)/ry)
+	if !sweep && dt > 0 {
+		dt -= 2 * math.Pi
+	} else if sweep && dt < 0 {
+		dt += 2 * math.Pi
+	}
+
+	// a Bezier for each quarter turn or less
+	n := int(math.Ceil(math.Abs(dt) / (math.Pi / 2)))
+	d := dt / float64(n)
+	k := (4.0 / 3.0) * math.Tan(d/4)
+	point := func(t float64) (float64, float64) {
+		ex, ey := rx*math.Cos(t), ry*math.Sin(t)
+		return cx + cosp*ex - sinp*ey, cy + sinp*ex + cosp*ey
+	}
+	tangent := func(t float64) (float64, float64) {
+		ex, ey := -rx*math.Sin(t), ry*math.Cos(t)
+		return cosp*ex - sinp*ey, sinp*ex + cosp*ey
+	}
+	ops := make([]PathOp, n)
+	for i := range ops {
+		a, b := t1+float64(i)*d, t1+float64(i+1)*d
+		ax, ay := point(a)
+		bx, by := point(b)
+		tax, tay := tangent(a)
+		tbx, tby := tangent(b)
+		ops[i] = PathOp{Op: 'C', X: []float64{ax + k*tax, bx - k*tbx, bx}, Y: []float64{ay + k*tay, by - k*tby, by}}
+	}
+	ops[n-1].X[2], ops[n-1].Y[2] = x2, y2
+	return ops
+}
+
+// Subpaths approximates the subpaths of a path with connected line segments;
+// closed subpaths end at their first point
+func Subpaths(ops []PathOp) ([][]float64, [][]float64) {
+	var px, py [][]float64
+	var x, y []float64
+	for _, op := range ops {
+		switch op.Op {
+		case 'M':
+			if len(x) > 1 {
+				px, py = append(px, x), append(py, y)
+			}
+			x, y = []float64{op.X[0]}, []float64{op.Y[0]}
+		case 'L':
+			x, y = append(x, op.X[0]), append(y, op.Y[0])
+		case 'Q':
+			cx, cy := CurvePoints(x[len(x)-1], y[len(y)-1], op.X[0], op.Y[0], op.X[1], op.Y[1])
+			x, y = append(x, cx[1:]...), append(y, cy[1:]...)
+		case 'C':
+			cx, cy := CubicPoints(x[len(x)-1], y[len(y)-1], op.X[0], op.Y[0], op.X[1], op.Y[1], op.X[2], op.Y[2])
+			x, y = append(x, cx[1:]...), append(y, cy[1:]...)
+		case 'Z':
+			if len(x) > 0 {
+				x, y = append(x, x[0]), append(y, y[0])
+			}
+		}
+	}
+	if len(x) > 1 {
+		px, py = append(px, x), append(py, y)
+	}
+	return px, py
+}
@@ -0,0 +1,669 @@
+// Package render walks deck slides, resolving defaults and percentage coordinates,
+// and draws each element through a Renderer
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"strconv"
+	"strings"
+
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	"github.com/ajstarks/deck"
+)
+
+const (
+	linespacing   = 1.4
+	listspacing   = 2.0
+	fontfactor    = 1.0
+	listwrap      = 95.0
+	defaultSw     = 2.0
+	defaultColor  = "rgb(127,127,127)"
+	codebg        = "rgb(240,240,240)"
+	tablesize     = 2.0
+	DefaultLayers = "image:rect:ellipse:curve:arc:line:poly:polyline:path:connector:table:chart:text:list:group"
+)
+
+// Style describes the resolved attributes used to draw an element
+type Style struct {
+	Color    string    // fill color for shapes and text, stroke color for lines
+	Opacity  float64   // opacity percentage: 0 is opaque, negative is fully transparent
+	Width    float64   // stroke width
+	Font     string    // font alias: sans, serif, mono, symbol
+	Size     float64   // font size
+	Align    string    // text alignment: begin, center, end
+	Link     string    // link reference
+	Bold     bool      // bold text
+	Italic   bool      // italic text
+	Stroke   string    // outline color of shapes, no outline if empty
+	NoFill   bool      // outline shapes without filling them
+	Dash     []float64 // dash pattern: lengths of dashes and gaps
+	Cap      string    // line cap: butt (the default), round, square
+	Join     string    // line join: miter (the default), round, bevel
+	Gradient *Gradient // gradient filling shapes in place of their color
+}
+
+// Renderer is implemented by backends that draw slides.
+// All coordinates and sizes are in canvas units, with the origin at the upper left,
+// x increasing to the right, and y increasing downward.
+type Renderer interface {
+	// Rect fills a rectangle with its upper left corner at (x,y);
+	// shapes are filled with the style's gradient, if any, and
+	// outlined (with a line Width wide) if the style has a stroke color
+	Rect(x, y, w, h float64, s Style)
+	// Ellipse fills an ellipse centered at (x,y) with radii (w,h)
+	Ellipse(x, y, w, h float64, s Style)
+	// Arc strokes an elliptical arc centered at (x,y) with radii (w,h), between angles a1 and a2 (degrees)
+	Arc(x, y, w, h, a1, a2 float64, s Style)
+	// Curve strokes a quadratic Bezier curve from (x1,y1) to (x3,y3), with control point (x2,y2)
+	Curve(x1, y1, x2, y2, x3, y3 float64, s Style)
+	// Line strokes a line from (x1,y1) to (x2,y2)
+	Line(x1, y1, x2, y2 float64, s Style)
+	// Polygon fills the polygon with vertices in x and y
+	Polygon(x, y []float64, s Style)
+	// Polyline strokes connected line segments through the points in x and y
+	Polyline(x, y []float64, s Style)
+	// Path fills a path made of lines and Bezier curves
+	Path(ops []PathOp, s Style)
+	// Text draws a single line of text with its baseline at y, aligned at x
+	Text(x, y float64, t string, s Style)
+	// TextWidth returns the width of text drawn with the specified font and size
+	TextWidth(t string, s Style) float64
+	// Image draws the named image centered at (x,y), scaled to (w,h)
+	Image(x, y, w, h float64, name string, s Style)
+	// Rotate rotates subsequent drawing counterclockwise by angle degrees about (x,y)
+	Rotate(x, y, angle float64)
+	// EndRotate ends the most recent rotation
+	EndRotate()
+}
+
+// Options control how slides are drawn
+type Options struct {
+	Layers     string  // colon-separated drawing order
+	Grid       float64 // if > 0, draw a grid at this percentage
+	StrictWrap bool    // wrap words before they cross the margin
+	Step       int     // if > 0, the build step to draw (see deck.Steps); otherwise all steps
+	// autoscale="on" reduces images wider than the canvas (as pdfdeck does),
+	// instead of enlarging narrower ones
+	ShrinkImages bool
+}
+
+var codemap = strings.NewReplacer("\t", "    ")
+
+// Pct converts percentages to canvas measures
+func Pct(p, m float64) float64 {
+	return (p / 100.0) * m
+}
+
+// Dimen returns canvas dimensions from percentages
+func Dimen(w, h, xp, yp, sp float64) (float64, float64, float64) {
+	return Pct(xp, w), Pct(100-yp, h), Pct(sp, w) * fontfactor
+}
+
+// Alpha converts an opacity percentage to a fraction:
+// 0 is the default value (opaque), negative values are fully transparent
+func Alpha(v float64) float64 {
+	switch {
+	case v < 0:
+		return 0
+	case v > 0:
+		return v / 100
+	}
+	return 1
+}
+
+// Color returns the color named by s (see deck.ParseColor) and its alpha (0-1),
+// combining the color's own alpha with the opacity percentage.
+// Unparsable colors are returned as black.
+func Color(s string, opacity float64) (color.RGBA, float64) {
+	c, _ := deck.ParseColor(s)
+	return c, Alpha(opacity) * float64(c.A) / 255
+}
+
+// Coords converts strings of space-separated percentages to canvas coordinates.
+// Values that do not parse are placed at the origin.
+func Coords(xc, yc string, cw, ch float64) ([]float64, []float64) {
+	xs := strings.Fields(xc)
+	ys := strings.Fields(yc)
+	if len(xs) != len(ys) {
+		return nil, nil
+	}
+	px := make([]float64, len(xs))
+	py := make([]float64, len(ys))
+	for i := range xs {
+		x, err := strconv.ParseFloat(xs[i], 64)
+		if err == nil {
+			px[i] = Pct(x, cw)
+		}
+		y, err := strconv.ParseFloat(ys[i], 64)
+		if err == nil {
+			py[i] = Pct(100-y, ch)
+		}
+	}
+	return px, py
+}
+
+// Includefile returns the contents of a file as a tab-expanded string
+func Includefile(filename string) string {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return ""
+	}
+	return codemap.Replace(string(data))
+}
+
+// ImageInfo returns the dimensions of an image
+func ImageInfo(s string) (int, int) {
+	f, err := os.Open(s)
+	if err != nil {
+		return 0, 0
+	}
+	defer f.Close()
+	im, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return 0, 0
+	}
+	return im.Width, im.Height
+}
+
+// whitespace determines if a rune is whitespace
+func whitespace(r rune) bool {
+	return r == ' ' || r == '\n' || r == '\t'
+}
+
+// Slide draws slide n of the deck
+func Slide(r Renderer, d deck.Deck, n int, o Options) {
+	if n < 0 || n > len(d.Slide)-1 {
+		return
+	}
+	cw := float64(d.Canvas.Width)
+	ch := float64(d.Canvas.Height)
+	slide := d.Slide[n]
+	if o.Step > 0 {
+		slide = build(slide, o.Step, slide.Dim)
+	}
+
+	// set default background
+	if slide.Bg == "" {
+		slide.Bg = "white"
+	}
+	r.Rect(0, 0, cw, ch, Style{Color: slide.Bg})
+
+	// set gradient background, if specified. You need both colors
+	if g := gradient(slide.Gradient, 0, 0, cw, ch); g != nil {
+		r.Rect(0, 0, cw, ch, Style{Color: slide.Bg, Gradient: g})
+	}
+	// set the default foreground
+	if slide.Fg == "" {
+		slide.Fg = "black"
+	}
+	elements(r, d, n, slide, o)
+	// add a grid, if specified
+	if o.Grid > 0 {
+		Grid(r, cw, ch, slide.Fg, o.Grid)
+	}
+}
+
+// elements draws the elements of a slide (or group) of deck d, in the order of the layer list;
+// text is drawn in the slide's foreground color unless specified
+func elements(r Renderer, d deck.Deck, n int, slide deck.Slide, o Options) {
+	cw := float64(d.Canvas.Width)
+	ch := float64(d.Canvas.Height)
+	layers := o.Layers
+	if layers == "" {
+		layers = DefaultLayers
+	}
+	// the elements of the slide's template are drawn under its own
+	for _, g := range slide.Group {
+		if g.Master {
+			group(r, d, n, slide.Fg, g, o)
+		}
+	}
+	for _, layer := range strings.Split(layers, ":") {
+		switch layer {
+		case "image":
+			for _, im := range slide.Image {
+				im.Caption = deck.Expand(im.Caption, d, n)
+				drawimage(r, cw, ch, im, slide.Fg, o.ShrinkImages)
+			}
+		case "rect":
+			for _, rect := range slide.Rect {
+				x, y, _ := Dimen(cw, ch, rect.Xp, rect.Yp, 0)
+				w, h := size(cw, ch, rect.Dimension)
+				st := shapestyle(cw, rect.Color, rect.Opacity, rect.Outline)
+				r.Rect(x-(w/2), y-(h/2), w, h, gradientfill(st, rect.Gradient, x-(w/2), y-(h/2), w, h))
+			}
+		case "ellipse":
+			for _, e := range slide.Ellipse {
+				x, y, _ := Dimen(cw, ch, e.Xp, e.Yp, 0)
+				w, h := size(cw, ch, e.Dimension)
+				st := shapestyle(cw, e.Color, e.Opacity, e.Outline)
+				r.Ellipse(x, y, w/2, h/2, gradientfill(st, e.Gradient, x-(w/2), y-(h/2), w, h))
+			}
+		case "curve":
+			for _, c := range slide.Curve {
+				x1, y1, sw := Dimen(cw, ch, c.Xp1, c.Yp1, c.Sp)
+				x2, y2, _ := Dimen(cw, ch, c.Xp2, c.Yp2, 0)
+				x3, y3, _ := Dimen(cw, ch, c.Xp3, c.Yp3, 0)
+				arrowcurve(r, cw, x1, y1, x2, y2, x3, y3, c.Arrowhead, linestyle(stroke(sw, c.Color, c.Opacity), c.LineStyle))
+			}
+		case "arc":
+			for _, a := range slide.Arc {
+				x, y, sw := Dimen(cw, ch, a.Xp, a.Yp, a.Sp)
+				w := Pct(a.Wp, cw)
+				h := Pct(a.Hp, cw)
+				r.Arc(x, y, w/2, h/2, a.A1, a.A2, linestyle(stroke(sw, a.Color, a.Opacity), a.LineStyle))
+			}
+		case "line":
+			for _, l := range slide.Line {
+				x1, y1, sw := Dimen(cw, ch, l.Xp1, l.Yp1, l.Sp)
+				x2, y2, _ := Dimen(cw, ch, l.Xp2, l.Yp2, 0)
+				arrowline(r, cw, []float64{x1, x2}, []float64{y1, y2}, l.Arrowhead, linestyle(stroke(sw, l.Color, l.Opacity), l.LineStyle))
+			}
+		case "poly":
+			for _, p := range slide.Polygon {
+				px, py := Coords(p.XC, p.YC, cw, ch)
+				if len(px) < 3 {
+					continue
+				}
+				bx, by, bw, bh := bounds(px, py)
+				r.Polygon(px, py, gradientfill(shapestyle(cw, p.Color, p.Opacity, p.Outline), p.Gradient, bx, by, bw, bh))
+			}
+		case "polyline":
+			for _, p := range slide.Polyline {
+				px, py := Coords(p.XC, p.YC, cw, ch)
+				if len(px) < 2 {
+					continue
+				}
+				_, _, sw := Dimen(cw, ch, 0, 0, p.Sp)
+				arrowline(r, cw, px, py, p.Arrowhead, linestyle(stroke(sw, p.Color, p.Opacity), p.LineStyle))
+			}
+		case "path":
+			for _, p := range slide.Path {
+				cmds, err := deck.ParsePath(p.D)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "%v\n", err)
+					continue
+				}
+				if len(cmds) < 2 {
+					continue
+				}
+				ops := pathops(cw, ch, cmds)
+				bx, by, bw, bh := pathbounds(ops)
+				r.Path(ops, gradientfill(shapestyle(cw, p.Color, p.Opacity, p.Outline), p.Gradient, bx, by, bw, bh))
+			}
+		case "connector":
+			ids := shapes(cw, ch, slide, o.ShrinkImages)
+			for _, c := range slide.Connector {
+				from, fok := ids[c.From]
+				to, tok := ids[c.To]
+				if !fok || !tok {
+					continue
+				}
+				_, _, sw := Dimen(cw, ch, 0, 0, c.Sp)
+				connector(r, cw, from, to, c, linestyle(stroke(sw, c.Color, c.Opacity), c.LineStyle))
+			}
+		case "table":
+			for _, t := range slide.Table {
+				if t.Color == "" {
+					t.Color = slide.Fg
+				}
+				if t.Font == "" {
+					t.Font = "sans"
+				}
+				if t.Sp == 0 {
+					t.Sp = tablesize
+				}
+				if t.Lp == 0 {
+					t.Lp = linespacing
+				}
+				rows := make([]deck.TableRow, len(t.Tr))
+				for i, tr := range t.Tr {
+					td := make([]deck.TableCell, len(tr.Td))
+					for j, c := range tr.Td {
+						c.Text = deck.Expand(c.Text, d, n)
+						td[j] = c
+					}
+					tr.Td = td
+					rows[i] = tr
+				}
+				t.Tr = rows
+				x, y, fs := Dimen(cw, ch, t.Xp, t.Yp, t.Sp)
+				table(r, cw, x, y, fs, t)
+			}
+		case "chart":
+			for _, c := range slide.Chart {
+				if c.Color == "" {
+					c.Color = slide.Fg
+				}
+				if c.Font == "" {
+					c.Font = "sans"
+				}
+				if c.Sp == 0 {
+					c.Sp = chartsize
+				}
+				x, y, fs := Dimen(cw, ch, c.Xp, c.Yp, c.Sp)
+				chart(r, x, y, Pct(c.Wp, cw), Pct(c.Hp, ch), fs, c)
+			}
+		case "text":
+			for _, t := range slide.Text {
+				if t.Color == "" {
+					t.Color = slide.Fg
+				}
+				if t.Font == "" {
+					t.Font = "sans"
+				}
+				if t.Lp == 0 {
+					t.Lp = linespacing
+				}
+				tdata := t.Tdata
+				var spans []deck.Span
+				if t.File != "" {
+					tdata = Includefile(t.File)
+				} else {
+					spans = deck.TextSpans(t.Markup, t.Tdata)
+					if t.Type != "code" {
+						tdata = deck.Expand(tdata, d, n)
+						for i := range spans {
+							spans[i].Text = deck.Expand(spans[i].Text, d, n)
+						}
+					}
+				}
+				x, y, fs := Dimen(cw, ch, t.Xp, t.Yp, t.Sp)
+				textcontent(r, cw, x, y, fs, tdata, spans, t, o.StrictWrap)
+			}
+		case "list":
+			for _, l := range slide.List {
+				if l.Color == "" {
+					l.Color = slide.Fg
+				}
+				if l.Font == "" {
+					l.Font = "sans"
+				}
+				if l.Lp == 0 {
+					l.Lp = listspacing
+				}
+				if l.Wp == 0 {
+					l.Wp = listwrap
+				}
+				li := make([]deck.ListItem, len(l.Li))
+				spans := make([][]deck.Span, len(l.Li))
+				for i, item := range l.Li {
+					spans[i] = deck.TextSpans(item.Markup, item.ListText)
+					item.ListText = deck.Expand(item.ListText, d, n)
+					li[i] = item
+					for j := range spans[i] {
+						spans[i][j].Text = deck.Expand(spans[i][j].Text, d, n)
+					}
+				}
+				l.Li = li
+				x, y, fs := Dimen(cw, ch, l.Xp, l.Yp, l.Sp)
+				list(r, cw, x, y, fs, l, spans, o.StrictWrap)
+			}
+		case "group":
+			for _, g := range slide.Group {
+				if !g.Master {
+					group(r, d, n, slide.Fg, g, o)
+				}
+			}
+		}
+	}
+}
+
+// size returns the width and height of a dimensioned object;
+// the height is relative to the width if hr is specified
+func size(cw, ch float64, d deck.Dimension) (float64, float64) {
+	w := Pct(d.Wp, cw)
+	if d.Hr == 0 {
+		return w, Pct(d.Hp, ch)
+	}
+	return w, Pct(d.Hr, w)
+}
+
+// fill returns the style of a filled shape, using the default color if unspecified
+func fill(color string, opacity float64) Style {
+	if color == "" {
+		color = defaultColor
+	}
+	return Style{Color: color, Opacity: opacity}
+}
+
+// stroke returns the style of a stroked shape, using the default color and width if unspecified
+func stroke(sw float64, color string, opacity float64) Style {
+	if sw == 0 {
+		sw = defaultSw
+	}
+	s := fill(color, opacity)
+	s.Width = sw
+	return s
+}
+
+// imagesize returns the width and height of an image in canvas units;
+// autoscaling enlarges images narrower than the canvas, or with shrink, reduces wider ones
+func imagesize(cw float64, im deck.Image, shrink bool) (float64, float64) {
+	fw, fh := float64(im.Width), float64(im.Height)
+	// scale the image by the specified percentage
+	if im.Scale > 0 {
+		fw *= (im.Scale / 100)
+		fh *= (im.Scale / 100)
+	}
+	// scale the image to fit the canvas width
+	if im.Autoscale == "on" && fw > 0 && (fw < cw && !shrink || fw > cw && shrink) {
+		fh *= (cw / fw)
+		fw = cw
+	}
+	// scale the image to a percentage of the canvas width
+	if im.Height == 0 && im.Width > 0 {
+		nw, nh := ImageInfo(im.Name)
+		if nh > 0 {
+			imscale := (fw / 100) * cw
+			fw = imscale
+			fh = imscale / (float64(nw) / float64(nh))
+		}
+	}
+	return fw, fh
+}
+
+// drawimage places an image and its caption
+func drawimage(r Renderer, cw, ch float64, im deck.Image, fg string, shrink bool) {
+	x, y, _ := Dimen(cw, ch, im.Xp, im.Yp, 0)
+	fw, fh := imagesize(cw, im, shrink)
+	r.Image(x, y, fw, fh, im.Name, Style{Opacity: im.Opacity, Link: im.Link})
+	if len(im.Caption) == 0 {
+		return
+	}
+	capsize := deck.Pwidth(im.Sp, cw, Pct(2, cw))
+	if im.Font == "" {
+		im.Font = "sans"
+	}
+	if im.Color == "" {
+		im.Color = fg
+	}
+	if im.Align == "" {
+		im.Align = "center"
+	}
+	midx := fw / 2
+	midy := fh / 2
+	switch im.Align {
+	case "left", "start":
+		x -= midx
+	case "right", "end":
+		x += midx
+	}
+	r.Text(x, y+midy+(capsize*1.5), im.Caption, Style{Color: im.Color, Font: im.Font, Size: capsize, Align: im.Align})
+}
+
+// textcontent places text elements on the canvas according to type;
+// spans, if any, are the styled runs of plain and block text
+func textcontent(r Renderer, cw, x, y, fs float64, tdata string, spans []deck.Span, t deck.Text, strict bool) {
+	st := Style{Color: t.Color, Opacity: t.Opacity, Font: t.Font, Size: fs, Align: t.Align, Link: t.Link}
+	if t.Rotation > 0 {
+		r.Rotate(x, y, t.Rotation)
+	}
+	switch t.Type {
+	case "code":
+		st.Font = "mono"
+		td := strings.Split(codemap.Replace(tdata), "\n")
+		ch := float64(len(td)) * t.Lp * fs
+		tw := deck.Pwidth(t.Wp, cw, cw-x-20)
+		r.Rect(x-fs, y-fs, tw, ch, Style{Color: codebg})
+		plaintext(r, td, x, y, t.Lp*fs, st)
+	case "block":
+		tw := deck.Pwidth(t.Wp, cw, cw/2)
+		if spans != nil {
+			flow(r, x, y, tw, fs*t.Lp, styled(spans, st))
+			break
+		}
+		textwrap(r, x, y, tw, fs*t.Lp, tdata, st, strict)
+	case "markdown":
+		tw := deck.Pwidth(t.Wp, cw, cw/2)
+		markdown(r, x, y, tw, fs*t.Lp, tdata, st)
+	default:
+		if spans != nil {
+			spanlines(r, x, y, t.Lp*fs, styled(spans, st), st.Align)
+			break
+		}
+		plaintext(r, strings.Split(codemap.Replace(tdata), "\n"), x, y, t.Lp*fs, st)
+	}
+	if t.Rotation > 0 {
+		r.EndRotate()
+	}
+}
+
+// plaintext places lines of text
+func plaintext(r Renderer, td []string, x, y, leading float64, st Style) {
+	for _, t := range td {
+		r.Text(x, y, t, st)
+		y += leading
+	}
+}
+
+// textwrap draws text at location, wrapping at the specified width,
+// returning the number of line breaks
+func textwrap(r Renderer, x, y, w, leading float64, s string, st Style, strict bool) int {
+	var factor = 0.3
+	if st.Font == "mono" {
+		factor = 1.0
+	}
+	st.Align = ""
+	nbreak := 0
+	wordspacing := r.TextWidth("M", st)
+	words := strings.FieldsFunc(s, whitespace)
+	xp := x
+	yp := y
+	edge := x + w
+	for i, s := range words {
+		if s == "\\n" { // magic new line
+			xp = x
+			yp += (leading * 1.5)
+			nbreak++
+			continue
+		}
+		tw := r.TextWidth(s, st)
+		if strict && xp+tw > edge && xp > x {
+			xp = x
+			yp += leading
+			nbreak++
+		}
+		r.Text(xp, yp, s, st)
+		xp += tw + (wordspacing * factor)
+		if !strict && xp > edge && i < len(words)-1 {
+			xp = x
+			yp += leading
+			nbreak++
+		}
+	}
+	return nbreak
+}
+
+// list places lists on the canvas; spans, if any, are the styled runs of each item
+func list(r Renderer, cw, x, y, fs float64, l deck.List, spans [][]deck.Span, strict bool) {
+	if l.Type == "bullet" {
+		x += fs * 1.2
+	}
+	ls := l.Lp * fs
+	tw := deck.Pwidth(l.Wp, cw, cw/2)
+	if l.Rotation > 0 {
+		r.Rotate(x, y, l.Rotation)
+	}
+	var t string
+	for i, tl := range l.Li {
+		st := Style{Color: l.Color, Opacity: l.Opacity, Font: l.Font, Size: fs, Align: l.Align}
+		if tl.Opacity != 0 {
+			st.Opacity = tl.Opacity
+		}
+		if l.Type == "number" {
+			t = fmt.Sprintf("%d. ", i+1) + tl.ListText
+		} else {
+			t = tl.ListText
+		}
+		if l.Type == "bullet" {
+			rs := fs / 4
+			r.Ellipse(x-fs, y-rs, rs, rs, Style{Color: l.Color, Opacity: st.Opacity})
+		}
+		if len(tl.Color) > 0 {
+			st.Color = tl.Color
+		}
+		if len(tl.Font) > 0 {
+			st.Font = tl.Font
+		}
+		var ss []span
+		if i < len(spans) && spans[i] != nil {
+			ss = styled(spans[i], st)
+			if l.Type == "number" {
+				ss = append([]span{{text: fmt.Sprintf("%d. ", i+1), st: st}}, ss...)
+			}
+		}
+		if l.Align == "center" || l.Align == "c" {
+			if ss != nil {
+				spanlines(r, x, y, ls, ss, l.Align)
+			} else {
+				r.Text(x, y, t, st)
+			}
+			y += ls
+			continue
+		}
+		var yw int
+		if ss != nil {
+			yw = flow(r, x, y, tw, ls, ss)
+		} else {
+			yw = textwrap(r, x, y, tw, ls, t, st, strict)
+		}
+		y += ls
+		if yw >= 1 {
+			y += ls * float64(yw)
+		}
+	}
+	if l.Rotation > 0 {
+		r.EndRotate()
+	}
+}
+
+// Grid makes a labeled percentage grid
+func Grid(r Renderer, w, h float64, color string, percent float64) {
+	pw := w * (percent / 100)
+	ph := h * (percent / 100)
+	ls := Style{Color: color, Width: 0.5}
+	ts := Style{Color: color, Font: "sans", Size: Pct(1, w), Align: "center"}
+	fs := ts.Size
+	for x, pl := 0.0, 0.0; x <= w; x += pw {
+		r.Line(x, 0, x, h, ls)
+		if pl > 0 {
+			r.Text(x, h-fs, fmt.Sprintf("%.0f", pl), ts)
+		}
+		pl += percent
+	}
+	for y, pl := 0.0, 0.0; y <= h; y += ph {
+		r.Line(0, y, w, y, ls)
+		if pl < 100 {
+			r.Text(fs, y+(fs/3), fmt.Sprintf("%.0f", 100-pl), ts)
+		}
+		pl += percent
+	}
+}
@@ -0,0 +1,358 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ajstarks/deck"
+)
+
+// recorder records drawing operations, measuring text at half the font size per rune
+type recorder struct {
+	ops []string
+}
+
+func (r *recorder) add(format string, args ...interface{}) {
+	r.ops = append(r.ops, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Rect(x, y, w, h float64, s Style) {
+	r.add("rect %.0f %.0f %.0f %.0f %s%s", x, y, w, h, s.Color, grad(s))
+}
+func (r *recorder) Ellipse(x, y, w, h float64, s Style) {
+	r.add("ellipse %.0f %.0f %.0f %.0f %s%s", x, y, w, h, s.Color, grad(s))
+}
+func (r *recorder) Arc(x, y, w, h, a1, a2 float64, s Style) {
+	r.add("arc %.0f %.0f %.0f %.0f %.0f %.0f", x, y, w, h, a1, a2)
+}
+func (r *recorder) Curve(x1, y1, x2, y2, x3, y3 float64, s Style) {
+	r.add("curve %.0f %.0f %.0f %.0f %.0f %.0f", x1, y1, x2, y2, x3, y3)
+}
+func (r *recorder) Line(x1, y1, x2, y2 float64, s Style) {
+	r.add("line %.0f %.0f %.0f %.0f %.0f %s", x1, y1, x2, y2, s.Width, s.Color)
+}
+func (r *recorder) Polygon(x, y []float64, s Style) {
+	r.add("polygon %v %v%s", x, y, grad(s))
+}
+func (r *recorder) Polyline(x, y []float64, s Style) {
+	r.add("polyline %v %v %.0f %s", x, y, s.Width, s.Color)
+}
+func (r *recorder) Path(ops []PathOp, s Style) {
+	var d []string
+	for _, op := range ops {
+		d = append(d, string(op.Op))
+		for i := range op.X {
+			d = append(d, fmt.Sprintf("%.0f,%.0f", op.X[i], op.Y[i]))
+		}
+	}
+	r.add("path %s %s%s", strings.Join(d, " "), s.Color, grad(s))
+}
+func (r *recorder) Text(x, y float64, t string, s Style) {
+	r.add("text %.0f %.0f %q %s %s", x, y, t, s.Font, s.Color)
+}
+func (r *recorder) TextWidth(t string, s Style) float64 {
+	return float64(len(t)) * s.Size / 2
+}
+func (r *recorder) Image(x, y, w, h float64, name string, s Style) {
+	r.add("image %.0f %.0f %.0f %.0f %s", x, y, w, h, name)
+}
+func (r *recorder) Rotate(x, y, angle float64) { r.add("rotate %.0f", angle) }
+func (r *recorder) EndRotate()                 { r.add("endrotate") }
+
+// grad describes the gradient fill of a style
+func grad(s Style) string {
+	g := s.Gradient
+	switch {
+	case g == nil:
+		return ""
+	case g.Radial:
+		return fmt.Sprintf(" radial %s %s %.0f %.0f %.0f", g.Color1, g.Color2, g.X1, g.Y1, g.R)
+	}
+	return fmt.Sprintf(" gradient %s %s %.0f %.0f %.0f %.0f", g.Color1, g.Color2, g.X1, g.Y1, g.X2, g.Y2)
+}
+
+func testdeck(s deck.Slide) deck.Deck {
+	var d deck.Deck
+	d.Canvas.Width = 1000
+	d.Canvas.Height = 500
+	d.Slide = []deck.Slide{s}
+	return d
+}
+
+func TestDefaults(t *testing.T) {
+	var s deck.Slide
+	s.Line = []deck.Line{{Xp1: 10, Yp1: 10, Xp2: 20, Yp2: 10}}
+	s.Rect = []deck.Rect{{}}
+	s.Rect[0].Xp, s.Rect[0].Yp, s.Rect[0].Wp, s.Rect[0].Hp = 50, 50, 10, 10
+	// autoscale only enlarges images narrower than the canvas
+	s.Image = []deck.Image{
+		{Name: "a.png", Width: 500, Height: 100, Autoscale: "on"},
+		{Name: "b.png", Width: 2000, Height: 100, Autoscale: "on"},
+	}
+	r := &recorder{}
+	Slide(r, testdeck(s), 0, Options{})
+	want := []string{
+		"rect 0 0 1000 500 white",
+		"image 0 500 1000 200 a.png",
+		"image 0 500 2000 100 b.png",
+		"rect 450 225 100 50 rgb(127,127,127)",
+		"line 100 450 200 450 2 rgb(127,127,127)",
+	}
+	if strings.Join(r.ops, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(r.ops, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestShrinkImages(t *testing.T) {
+	var s deck.Slide
+	s.Image = []deck.Image{
+		{Name: "a.png", Width: 500, Height: 100, Autoscale: "on"},
+		{Name: "b.png", Width: 2000, Height: 100, Autoscale: "on"},
+	}
+	r := &recorder{}
+	Slide(r, testdeck(s), 0, Options{ShrinkImages: true})
+	want := []string{
+		"rect 0 0 1000 500 white",
+		"image 0 500 500 100 a.png",
+		"image 0 500 1000 50 b.png",
+	}
+	if strings.Join(r.ops, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(r.ops, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestListWrap(t *testing.T) {
+	var s deck.Slide
+	l := deck.List{Wp: 10, Li: []deck.ListItem{{ListText: "aaaa bbbb cccc"}, {ListText: "dd"}}}
+	l.Xp, l.Yp, l.Sp, l.Lp = 10, 50, 2, 2
+	s.List = []deck.List{l}
+	r := &recorder{}
+	Slide(r, testdeck(s), 0, Options{Layers: "list"})
+	// size is 20, the wrap width is 100: each word is 40 wide, spaced by 3
+	want := []string{
+		"rect 0 0 1000 500 white",
+		`text 100 250 "aaaa" sans black`,
+		`text 143 250 "bbbb" sans black`,
+		`text 186 250 "cccc" sans black`,
+		`text 100 290 "dd" sans black`,
+	}
+	if strings.Join(r.ops, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(r.ops, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestPolyline(t *testing.T) {
+	var s deck.Slide
+	s.Polyline = []deck.Polyline{
+		{XC: "10 20 30", YC: "50 60 50", Sp: 0.5, Color: "red"},
+		{XC: "10", YC: "10"},
+	}
+	r := &recorder{}
+	Slide(r, testdeck(s), 0, Options{Layers: "polyline"})
+	want := []string{
+		"rect 0 0 1000 500 white",
+		"polyline [100 200 300] [250 200 250] 5 red",
+	}
+	if strings.Join(r.ops, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(r.ops, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestAlpha(t *testing.T) {
+	tests := []struct{ in, out float64 }{{0, 1}, {-1, 0}, {50, 0.5}, {100, 1}}
+	for _, tc := range tests {
+		if got := Alpha(tc.in); got != tc.out {
+			t.Errorf("Alpha(%v) = %v, want %v", tc.in, got, tc.out)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	var s deck.Slide
+	s.Text = []deck.Text{{Tdata: "{{slidenumber}} / {{slidecount}}"}, {Tdata: "{{title}}"}}
+	s.Text[1].Type = "code"
+	d := testdeck(s)
+	d.Slide = append(d.Slide, deck.Slide{})
+	r := &recorder{}
+	Slide(r, d, 0, Options{Layers: "text"})
+	if len(r.ops) != 4 || r.ops[1] != `text 0 500 "1 / 2" sans black` || r.ops[3] != `text 0 500 "{{title}}" mono black` {
+		t.Errorf("got %q", r.ops)
+	}
+}
+
+func TestTable(t *testing.T) {
+	var s deck.Slide
+	tab := deck.Table{Widths: "30", Aligns: "begin end", Header: 1, Border: "gray", Stripe: "silver"}
+	tab.Xp, tab.Yp = 10, 90
+	tab.Tr = []deck.TableRow{
+		{Td: []deck.TableCell{{Text: "Name"}, {Text: "Total"}}},
+		{Td: []deck.TableCell{{Text: "alpha beta gamma delta epsilon"}, {Text: "12"}}},
+		{Td: []deck.TableCell{{Text: "slide"}, {Text: "{{slidenumber}}", Color: "red"}}},
+	}
+	s.Table = []deck.Table{tab}
+	r := &recorder{}
+	Slide(r, testdeck(s), 0, Options{Layers: "table"})
+	want := []string{
+		"rect 0 0 1000 500 white",
+		`text 110 80 "Name" sans black`,
+		`text 890 80 "Total" sans black`,
+		`text 110 120 "alpha beta gamma delta" sans black`,
+		`text 110 148 "epsilon" sans black`,
+		`text 890 120 "12" sans black`,
+		"rect 100 158 800 40 silver",
+		`text 110 188 "slide" sans black`,
+		`text 890 188 "1" sans red`,
+		"line 100 50 900 50 2 gray",
+		"line 100 90 900 90 2 gray",
+		"line 100 158 900 158 2 gray",
+		"line 100 198 900 198 2 gray",
+		"line 100 50 100 198 2 gray",
+		"line 400 50 400 198 2 gray",
+		"line 900 50 900 198 2 gray",
+	}
+	if strings.Join(r.ops, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(r.ops, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestGroup(t *testing.T) {
+	var s deck.Slide
+	inner := deck.Group{Tx: 5, Color: "blue"}
+	inner.Line = []deck.Line{{Xp1: 50, Yp1: 50, Xp2: 60, Yp2: 50, Sp: 1}}
+	g := deck.Group{Tx: 10, Scale: 200, Xp: 50, Yp: 50, Rotation: 90, Color: "red", Group: []deck.Group{inner}}
+	g.Rect = []deck.Rect{{}}
+	g.Rect[0].Xp, g.Rect[0].Yp, g.Rect[0].Wp, g.Rect[0].Hp = 50, 50, 10, 10
+	g.Text = []deck.Text{{Tdata: "label"}}
+	g.Text[0].Xp, g.Text[0].Yp, g.Text[0].Sp = 40, 40, 1
+	s.Group = []deck.Group{g}
+	r := &recorder{}
+	Slide(r, testdeck(s), 0, Options{})
+	want := []string{
+		"rect 0 0 1000 500 white",
+		"rotate 90",
+		"rect 500 200 200 100 red",
+		`text 400 350 "label" sans red`,
+		"line 700 250 900 250 20 blue",
+		"endrotate",
+	}
+	if strings.Join(r.ops, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(r.ops, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	markup := `<deck><canvas width="1000" height="500"/>
+<template name="std"><rect xp="50" yp="10" wp="100" hp="20" color="gray"/></template>
+<slide template="std"><image xp="50" yp="10" width="100" height="50" name="a.png"/><rect xp="10" yp="50" wp="10" hp="10" color="red"/></slide>
+</deck>`
+	d, err := deck.ReadDeck(io.NopCloser(strings.NewReader(markup)), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the template's panel is under the slide's image, although rects are drawn after images
+	r := &recorder{}
+	Slide(r, d, 0, Options{})
+	want := []string{
+		"rect 0 0 1000 500 white",
+		"rect 0 400 1000 100 gray",
+		"image 500 450 100 50 a.png",
+		"rect 50 225 100 50 red",
+	}
+	if strings.Join(r.ops, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(r.ops, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestPath(t *testing.T) {
+	var s deck.Slide
+	s.Path = []deck.Path{{D: "M 10 10 L 20 10 A 10 10 0 0 1 40 10 Z", Color: "red"}, {D: "M 10 10 X"}}
+	r := &recorder{}
+	Slide(r, testdeck(s), 0, Options{Layers: "path"})
+	want := []string{
+		"rect 0 0 1000 500 white",
+		"path M 100,450 L 200,450 C 200,505 245,550 300,550 C 355,550 400,505 400,450 Z red",
+	}
+	if strings.Join(r.ops, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(r.ops, "\n"), strings.Join(want, "\n"))
+	}
+	tr := transform{s: 2, ax: 10, ay: 10, tx: 5}
+	if got, want := tr.path("M 10 10 a 5 5 0 0 1 10 0"), "M 15 10 A 10 10 0 0 1 35 10"; got != want {
+		t.Errorf("transformed path: got %q, want %q", got, want)
+	}
+}
+
+func TestArrows(t *testing.T) {
+	var s deck.Slide
+	s.Line = []deck.Line{{Xp1: 10, Yp1: 50, Xp2: 50, Yp2: 50, Sp: 0.2, Color: "red", Arrowhead: deck.Arrowhead{Arrow: "end"}}}
+	s.Rect = []deck.Rect{{}, {}}
+	s.Rect[0].ID, s.Rect[0].Xp, s.Rect[0].Yp, s.Rect[0].Wp, s.Rect[0].Hp = "a", 20, 20, 10, 10
+	s.Rect[1].ID, s.Rect[1].Xp, s.Rect[1].Yp, s.Rect[1].Wp, s.Rect[1].Hp = "b", 80, 20, 10, 10
+	s.Connector = []deck.Connector{{From: "a", To: "b", Color: "blue", Arrowhead: deck.Arrowhead{Arrow: "both", ArrowStyle: "open"}}, {From: "a", To: "c"}}
+	r := &recorder{}
+	Slide(r, testdeck(s), 0, Options{Layers: "line:connector"})
+	want := []string{
+		"rect 0 0 1000 500 white",
+		"polygon [500 490 490] [250 254 246]",
+		"line 100 250 495 250 2 red",
+		"polyline [260 250 260] [396 400 404] 2 blue",
+		"polyline [740 750 740] [404 400 396] 2 blue",
+		"line 250 400 750 400 2 blue",
+	}
+	if strings.Join(r.ops, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(r.ops, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestGradient(t *testing.T) {
+	var s deck.Slide
+	s.Gradient = deck.Gradient{Gradcolor1: "red", Gradcolor2: "blue"}
+	s.Rect = []deck.Rect{{}, {}}
+	s.Rect[0].Xp, s.Rect[0].Yp, s.Rect[0].Wp, s.Rect[0].Hp = 50, 50, 20, 20
+	s.Rect[0].Gradient = deck.Gradient{Gradcolor1: "white", Gradcolor2: "black", GradAngle: 90, GradPercent: 50}
+	s.Rect[1].Xp, s.Rect[1].Yp, s.Rect[1].Wp, s.Rect[1].Hp, s.Rect[1].Fill = 50, 50, 20, 20, "none"
+	s.Rect[1].Gradient = s.Rect[0].Gradient
+	s.Ellipse = []deck.Ellipse{{}}
+	s.Ellipse[0].Xp, s.Ellipse[0].Yp, s.Ellipse[0].Wp, s.Ellipse[0].Hp = 50, 50, 10, 40
+	s.Ellipse[0].Gradient = deck.Gradient{Gradcolor1: "white", Gradcolor2: "green", GradType: "radial"}
+	s.Polygon = []deck.Polygon{{XC: "10 30 20", YC: "10 10 30", Gradient: deck.Gradient{Gradcolor1: "red", Gradcolor2: "blue", GradAngle: 180}}}
+	r := &recorder{}
+	Slide(r, testdeck(s), 0, Options{Layers: "rect:ellipse:poly"})
+	want := []string{
+		"rect 0 0 1000 500 white",
+		"rect 0 0 1000 500 white gradient red blue 500 0 500 500",
+		"rect 400 200 200 100 rgb(127,127,127) gradient white black 400 250 500 250",
+		"rect 400 200 200 100 rgb(127,127,127)",
+		"ellipse 500 250 50 100 rgb(127,127,127) radial white green 500 250 100",
+		"polygon [100 300 200] [450 450 350] gradient red blue 200 450 200 350",
+	}
+	if strings.Join(r.ops, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(r.ops, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestBuild(t *testing.T) {
+	var s deck.Slide
+	s.Rect = []deck.Rect{{}, {}, {}}
+	s.Rect[1].Step, s.Rect[2].Step = 1, 3
+	s.List = []deck.List{{Build: 2, Li: []deck.ListItem{{ListText: "a"}, {ListText: "b"}}}}
+	s.Group = []deck.Group{{Step: 1, Rect: []deck.Rect{{}}}}
+	if n := deck.Steps(s); n != 3 {
+		t.Errorf("steps: got %d, want 3", n)
+	}
+	b := build(s, 2, 40)
+	if len(b.Rect) != 2 || b.Rect[0].Opacity != 0 || b.Rect[1].Opacity != 40 {
+		t.Errorf("rects at step 2: %+v", b.Rect)
+	}
+	if li := b.List[0].Li; len(li) != 1 || li[0].Opacity != 0 {
+		t.Errorf("list items at step 2: %+v", li)
+	}
+	if g := b.Group[0]; g.Opacity != 40 || g.Rect[0].Opacity != 0 {
+		t.Errorf("group at step 2: %+v", g)
+	}
+	b = build(s, 3, 40)
+	if li := b.List[0].Li; len(li) != 2 || li[0].Opacity != 40 || li[1].Opacity != 0 {
+		t.Errorf("list items at step 3: %+v", li)
+	}
+}
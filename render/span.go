@@ -0,0 +1,129 @@
+package render
+
+import (
+	"strings"
+
+	"github.com/ajstarks/deck"
+)
+
+// span is a run of text drawn in a single style
+type span struct {
+	text string
+	st   Style
+}
+
+// styled converts the inline spans of text or list items to spans
+// drawn in the element's style, as modified by each span
+func styled(spans []deck.Span, st Style) []span {
+	ss := make([]span, len(spans))
+	for i, sp := range spans {
+		s := st
+		if sp.Color != "" {
+			s.Color = sp.Color
+		}
+		if sp.Font != "" {
+			s.Font = sp.Font
+		}
+		if sp.Opacity != 0 {
+			s.Opacity = sp.Opacity
+		}
+		if sp.Link != "" {
+			s.Link = sp.Link
+		}
+		s.Bold = s.Bold || sp.Bold
+		s.Italic = s.Italic || sp.Italic
+		ss[i] = span{text: sp.Text, st: s}
+	}
+	return ss
+}
+
+// flow draws styled spans starting at (x,y), wrapping words at the
+// specified width, returning the number of line breaks.
+// Spans not separated by white space are kept together on a line.
+func flow(r Renderer, x, y, w, leading float64, spans []span) int {
+	type piece struct {
+		text  string
+		st    Style
+		width float64
+	}
+	// split spans into words made of one or more pieces
+	var words [][]piece
+	joined := false
+	for _, sp := range spans {
+		sp.st.Align = ""
+		fields := strings.FieldsFunc(sp.text, whitespace)
+		for i, f := range fields {
+			p := piece{text: f, st: sp.st, width: r.TextWidth(f, sp.st)}
+			if i == 0 && joined && len(words) > 0 && !whitespace(rune(sp.text[0])) {
+				words[len(words)-1] = append(words[len(words)-1], p)
+			} else {
+				words = append(words, []piece{p})
+			}
+		}
+		joined = len(fields) > 0 && !whitespace(rune(sp.text[len(sp.text)-1]))
+	}
+
+	nbreak := 0
+	xp, yp := x, y
+	edge := x + w
+	for _, word := range words {
+		ww := 0.0
+		for _, p := range word {
+			ww += p.width
+		}
+		if xp+ww > edge && xp > x {
+			xp = x
+			yp += leading
+			nbreak++
+		}
+		for _, p := range word {
+			r.Text(xp, yp, p.text, p.st)
+			xp += p.width
+		}
+		last := word[len(word)-1].st
+		factor := 0.3
+		if last.Font == "mono" {
+			factor = 1.0
+		}
+		xp += r.TextWidth("M", last) * factor
+	}
+	return nbreak
+}
+
+// spanlines draws lines of styled spans, breaking at new lines,
+// with each line aligned at x
+func spanlines(r Renderer, x, y, leading float64, spans []span, align string) {
+	var line []span
+	draw := func() {
+		w := 0.0
+		for i := range line {
+			line[i].st.Align = ""
+			w += r.TextWidth(line[i].text, line[i].st)
+		}
+		xp := x
+		switch align {
+		case "center", "middle", "mid", "c":
+			xp -= w / 2
+		case "right", "end", "e":
+			xp -= w
+		}
+		for _, s := range line {
+			r.Text(xp, y, s.text, s.st)
+			xp += r.TextWidth(s.text, s.st)
+		}
+		line = line[:0]
+		y += leading
+	}
+	for _, s := range spans {
+		parts := strings.Split(codemap.Replace(s.text), "\n")
+		for i, p := range parts {
+			if i > 0 {
+				draw()
+			}
+			if p != "" {
+				line = append(line, span{text: p, st: s.st})
+			}
+		}
+	}
+	draw()
+}
@@ -0,0 +1,61 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ajstarks/deck"
+)
+
+func TestFlow(t *testing.T) {
+	r := &recorder{}
+	spans := []span{{"aaaa b", Style{Size: 10}}, {"bb", Style{Size: 10, Bold: true}}, {" cc", Style{Size: 10}}}
+	n := flow(r, 0, 0, 25, 10, spans)
+	want := []string{
+		`text 0 0 "aaaa"  `,
+		`text 0 10 "b"  `,
+		`text 5 10 "bb"  `,
+		`text 0 20 "cc"  `,
+	}
+	if n != 2 || strings.Join(r.ops, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %d breaks\n%s\nwant 2\n%s", n, strings.Join(r.ops, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestSpans(t *testing.T) {
+	var s deck.Slide
+	s.Text = []deck.Text{
+		{Tdata: "Sales are down 5%", Markup: `Sales are <span color="red">down</span> <b>5%</b>`},
+		{Tdata: "a b", Markup: `a <i>b</i>`},
+		{Tdata: "edited", Markup: `a <i>b</i>`},
+	}
+	s.Text[0].Xp, s.Text[0].Yp, s.Text[0].Sp = 10, 90, 2
+	s.Text[1].Xp, s.Text[1].Yp, s.Text[1].Sp, s.Text[1].Align = 50, 50, 2, "center"
+	s.Text[2].Xp, s.Text[2].Yp, s.Text[2].Sp = 50, 30, 2
+	s.List = []deck.List{{Li: []deck.ListItem{
+		{ListText: "x {{slidenumber}}", Markup: `<span font="mono">x</span> {{slidenumber}}`},
+		{ListText: "edited", Markup: `<span font="mono">x</span>`},
+	}}}
+	s.List[0].Xp, s.List[0].Yp, s.List[0].Sp, s.List[0].Type = 10, 10, 2, "number"
+	// text changed since it was read is shown in place of its markup
+	r := &recorder{}
+	Slide(r, testdeck(s), 0, Options{Layers: "text:list"})
+	want := []string{
+		`rect 0 0 1000 500 white`,
+		`text 100 50 "Sales are " sans black`,
+		`text 200 50 "down" sans red`,
+		`text 240 50 " " sans black`,
+		`text 250 50 "5%" sans black`,
+		`text 485 250 "a " sans black`,
+		`text 505 250 "b" sans black`,
+		`text 500 350 "edited" sans black`,
+		`text 100 450 "1." sans black`,
+		`text 123 450 "x" mono black`,
+		`text 143 450 "1" sans black`,
+		`text 100 490 "2." sans black`,
+		`text 123 490 "edited" sans black`,
+	}
+	if strings.Join(r.ops, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(r.ops, "\n"), strings.Join(want, "\n"))
+	}
+}
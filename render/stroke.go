@@ -0,0 +1,139 @@
+package render
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ajstarks/deck"
+)
+
+// linestyle applies the dash pattern, cap and join of an element to a stroke style;
+// dash lengths are multiples of the stroke width
+func linestyle(s Style, ls deck.LineStyle) Style {
+	total := 0.0
+	for _, f := range strings.Fields(ls.Dash) {
+		if v, err := strconv.ParseFloat(f, 64); err == nil && v >= 0 {
+			s.Dash = append(s.Dash, v*s.Width)
+			total += v
+		}
+	}
+	if total == 0 {
+		s.Dash = nil
+	}
+	s.Cap, s.Join = ls.Linecap, ls.Linejoin
+	return s
+}
+
+// shapestyle returns the style of a shape: filled with its color (or fill, unless "none"),
+// and outlined with its stroke; unfilled shapes without a stroke are outlined in their color
+func shapestyle(cw float64, color string, opacity float64, o deck.Outline) Style {
+	s := fill(color, opacity)
+	switch o.Fill {
+	case "":
+	case "none":
+		s.NoFill = true
+	default:
+		s.Color = o.Fill
+	}
+	s.Stroke = o.Stroke
+	if s.NoFill && s.Stroke == "" {
+		s.Stroke = s.Color
+	}
+	if s.Stroke == "" {
+		return s
+	}
+	s.Width = Pct(o.StrokeWidth, cw)
+	if s.Width == 0 {
+		s.Width = defaultSw
+	}
+	return linestyle(s, o.LineStyle)
+}
+
+// Dashes breaks the connected line segments through the points in x and y
+// into dashes, following a pattern of dash and gap lengths.
+// Without a pattern, the line is a single dash.
+func Dashes(x, y, pattern []float64) ([][]float64, [][]float64) {
+	total := 0.0
+	for _, d := range pattern {
+		total += d
+	}
+	if total <= 0 || len(x) < 2 {
+		return [][]float64{x}, [][]float64{y}
+	}
+	if len(pattern)%2 == 1 {
+		pattern = append(pattern[:len(pattern):len(pattern)], pattern...)
+	}
+	var dx, dy [][]float64
+	cx, cy := []float64{x[0]}, []float64{y[0]}
+	i, rem, on := 0, pattern[0], true
+	for k := 1; k < len(x); k++ {
+		x0, y0 := x[k-1], y[k-1]
+		seg := math.Hypot(x[k]-x0, y[k]-y0)
+		pos := 0.0
+		for seg-pos > rem {
+			pos += rem
+			px, py := x0+(x[k]-x0)*pos/seg, y0+(y[k]-y0)*pos/seg
+			if on {
+				dx, dy = append(dx, append(cx, px)), append(dy, append(cy, py))
+			} else {
+				cx, cy = []float64{px}, []float64{py}
+			}
+			on = !on
+			i = (i + 1) % len(pattern)
+			rem = pattern[i]
+		}
+		rem -= seg - pos
+		if on {
+			cx, cy = append(cx, x[k]), append(cy, y[k])
+		}
+	}
+	if on && len(cx) > 1 {
+		dx, dy = append(dx, cx), append(dy, cy)
+	}
+	return dx, dy
+}
+
+// ArcPoints approximates an elliptical arc centered at (x,y) with radii (w,h),
+// from angle a1 to a2 (degrees, counterclockwise), with points every 5 degrees
+func ArcPoints(x, y, w, h, a1, a2 float64) ([]float64, []float64) {
+	steps := int(math.Ceil(math.Abs(a2-a1)/5)) + 1
+	px := make([]float64, steps+1)
+	py := make([]float64, steps+1)
+	for i := 0; i <= steps; i++ {
+		t := (a1 + (a2-a1)*float64(i)/float64(steps)) * (math.Pi / 180)
+		px[i] = x + w*math.Cos(t)
+		py[i] = y - h*math.Sin(t)
+	}
+	return px, py
+}
+
+// CurvePoints approximates a quadratic Bezier curve from (x1,y1) to (x3,y3),
+// with control point (x2,y2)
+func CurvePoints(x1, y1, x2, y2, x3, y3 float64) ([]float64, []float64) {
+	const steps = 24
+	px := make([]float64, steps+1)
+	py := make([]float64, steps+1)
+	for i := 0; i <= steps; i++ {
+		t := float64(i) / steps
+		u := 1 - t
+		px[i] = u*u*x1 + 2*u*t*x2 + t*t*x3
+		py[i] = u*u*y1 + 2*u*t*y2 + t*t*y3
+	}
+	return px, py
+}
+
+// CubicPoints approximates a cubic Bezier curve from (x1,y1) to (x4,y4),
+// with control points (x2,y2) and (x3,y3)
+func CubicPoints(x1, y1, x2, y2, x3, y3, x4, y4 float64) ([]float64, []float64) {
+	const steps = 24
+	px := make([]float64, steps+1)
+	py := make([]float64, steps+1)
+	for i := 0; i <= steps; i++ {
+		t := float64(i) / steps
+		u := 1 - t
+		px[i] = u*u*u*x1 + 3*u*u*t*x2 + 3*u*t*t*x3 + t*t*t*x4
+		py[i] = u*u*u*y1 + 3*u*u*t*y2 + 3*u*t*t*y3 + t*t*t*y4
+	}
+	return px, py
+}
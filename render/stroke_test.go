@@ -0,0 +1,48 @@
+package render
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/ajstarks/deck"
+)
+
+func TestShapestyle(t *testing.T) {
+	tests := []struct {
+		color string
+		o     deck.Outline
+		want  Style
+	}{
+		{"red", deck.Outline{}, Style{Color: "red"}},
+		{"red", deck.Outline{Fill: "blue"}, Style{Color: "blue"}},
+		{"red", deck.Outline{Fill: "none"}, Style{Color: "red", NoFill: true, Stroke: "red", Width: defaultSw}},
+		{"red", deck.Outline{Stroke: "black", StrokeWidth: 0.5}, Style{Color: "red", Stroke: "black", Width: 5}},
+		{"", deck.Outline{Stroke: "black", StrokeWidth: 0.5, LineStyle: deck.LineStyle{Dash: "4 2", Linejoin: "round"}},
+			Style{Color: defaultColor, Stroke: "black", Width: 5, Dash: []float64{20, 10}, Join: "round"}},
+		{"red", deck.Outline{Stroke: "black", LineStyle: deck.LineStyle{Dash: "0 0"}}, Style{Color: "red", Stroke: "black", Width: defaultSw}},
+	}
+	for _, test := range tests {
+		if got := shapestyle(1000, test.color, 0, test.o); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("shapestyle(%q, %+v) = %+v, want %+v", test.color, test.o, got, test.want)
+		}
+	}
+}
+
+func TestDashes(t *testing.T) {
+	tests := []struct {
+		x, y, pattern []float64
+		want          string
+	}{
+		{[]float64{0, 10}, []float64{0, 0}, nil, "[[0 10]] [[0 0]]"},
+		{[]float64{0, 10}, []float64{0, 0}, []float64{3, 2}, "[[0 3] [5 8]] [[0 0] [0 0]]"},
+		{[]float64{0, 10}, []float64{0, 0}, []float64{4}, "[[0 4] [8 10]] [[0 0] [0 0]]"},
+		{[]float64{0, 2, 2}, []float64{0, 0, 4}, []float64{3, 1}, "[[0 2 2] [2 2]] [[0 0 1] [2 4]]"},
+	}
+	for _, test := range tests {
+		dx, dy := Dashes(test.x, test.y, test.pattern)
+		if got := fmt.Sprint(dx, dy); got != test.want {
+			t.Errorf("Dashes(%v, %v, %v) = %s, want %s", test.x, test.y, test.pattern, got, test.want)
+		}
+	}
+}
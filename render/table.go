@@ -0,0 +1,191 @@
+package render
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ajstarks/deck"
+)
+
+// cell is a table cell laid out for drawing
+type cell struct {
+	lines []string
+	st    Style
+	bg    string
+}
+
+// colwidths returns the widths of the columns of a table in canvas units:
+// widths not specified share what remains of the table width
+func colwidths(cw, x float64, t deck.Table) []float64 {
+	n := 0
+	for _, tr := range t.Tr {
+		if len(tr.Td) > n {
+			n = len(tr.Td)
+		}
+	}
+	specified := strings.Fields(t.Widths)
+	if len(specified) > n {
+		n = len(specified)
+	}
+	widths := make([]float64, n)
+	tw := Pct(t.Wp, cw)
+	if tw <= 0 {
+		tw = cw - (2 * x)
+	}
+	rest, unspecified := tw, 0
+	for i := range widths {
+		if i < len(specified) {
+			if w, err := strconv.ParseFloat(specified[i], 64); err == nil && w > 0 {
+				widths[i] = Pct(w, cw)
+				rest -= widths[i]
+				continue
+			}
+		}
+		unspecified++
+	}
+	for i := range widths {
+		if widths[i] == 0 && unspecified > 0 && rest > 0 {
+			widths[i] = rest / float64(unspecified)
+		}
+	}
+	return widths
+}
+
+// alignment normalizes the alignment of a cell
+func alignment(s string) string {
+	switch s {
+	case "center", "c":
+		return "center"
+	case "end", "right":
+		return "end"
+	}
+	return "begin"
+}
+
+// wraplines breaks text into lines no wider than w
+func wraplines(r Renderer, s string, w float64, st Style) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.FieldsFunc(s, whitespace) {
+		if line != "" && r.TextWidth(line+" "+word, st) > w {
+			lines = append(lines, line)
+			line = word
+			continue
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// table draws a table with its upper left corner at (x,y), and text of size fs
+func table(r Renderer, cw, x, y, fs float64, t deck.Table) {
+	widths := colwidths(cw, x, t)
+	if len(widths) == 0 {
+		return
+	}
+	tw := 0.0
+	for _, w := range widths {
+		tw += w
+	}
+	pad := fs / 2
+	leading := fs * t.Lp
+	aligns := strings.Fields(t.Aligns)
+	if t.Rotation > 0 {
+		r.Rotate(x, y, t.Rotation)
+	}
+
+	// lay out the cells, finding the height of each row
+	cells := make([][]cell, len(t.Tr))
+	heights := make([]float64, len(t.Tr))
+	for i, tr := range t.Tr {
+		nlines := 1
+		cells[i] = make([]cell, len(tr.Td))
+		for j, td := range tr.Td {
+			st := Style{Color: t.Color, Opacity: t.Opacity, Font: t.Font, Size: fs, Bold: i < t.Header}
+			if tr.Color != "" {
+				st.Color = tr.Color
+			}
+			if tr.Font != "" {
+				st.Font = tr.Font
+			}
+			if td.Color != "" {
+				st.Color = td.Color
+			}
+			if td.Font != "" {
+				st.Font = td.Font
+			}
+			st.Align = t.Align
+			if j < len(aligns) {
+				st.Align = aligns[j]
+			}
+			if td.Align != "" {
+				st.Align = td.Align
+			}
+			st.Align = alignment(st.Align)
+			lines := wraplines(r, td.Text, widths[j]-(2*pad), st)
+			if len(lines) > nlines {
+				nlines = len(lines)
+			}
+			cells[i][j] = cell{lines: lines, st: st, bg: td.Bg}
+		}
+		heights[i] = (2 * pad) + fs + (float64(nlines-1) * leading)
+	}
+
+	// backgrounds, then text
+	ry := y
+	for i, tr := range t.Tr {
+		bg := tr.Bg
+		if bg == "" && t.Stripe != "" && i >= t.Header && (i-t.Header)%2 == 1 {
+			bg = t.Stripe
+		}
+		if bg != "" {
+			r.Rect(x, ry, tw, heights[i], Style{Color: bg})
+		}
+		cx := x
+		for j, c := range cells[i] {
+			if c.bg != "" {
+				r.Rect(cx, ry, widths[j], heights[i], Style{Color: c.bg})
+			}
+			tx := cx + pad
+			switch c.st.Align {
+			case "center":
+				tx = cx + (widths[j] / 2)
+			case "end":
+				tx = cx + widths[j] - pad
+			}
+			ty := ry + pad + fs
+			for _, line := range c.lines {
+				r.Text(tx, ty, line, c.st)
+				ty += leading
+			}
+			cx += widths[j]
+		}
+		ry += heights[i]
+	}
+
+	// borders around every cell
+	if t.Border != "" {
+		bs := Style{Color: t.Border, Width: fs / 10}
+		ly := y
+		r.Line(x, ly, x+tw, ly, bs)
+		for _, h := range heights {
+			ly += h
+			r.Line(x, ly, x+tw, ly, bs)
+		}
+		lx := x
+		r.Line(lx, y, lx, ly, bs)
+		for _, w := range widths {
+			lx += w
+			r.Line(lx, y, lx, ly, bs)
+		}
+	}
+	if t.Rotation > 0 {
+		r.EndRotate()
+	}
+}
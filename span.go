@@ -0,0 +1,115 @@
+package deck
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+)
+
+// Span is a run of styled text within text or list item content,
+// marked up with the inline elements <b>, <i> and <span>:
+// <text>Sales are <span color="red">down</span> <b>5%</b></text>
+type Span struct {
+	Text    string
+	Color   string
+	Font    string
+	Opacity float64
+	Link    string
+	Bold    bool
+	Italic  bool
+}
+
+// Spans returns the styled runs of text or list item markup,
+// or nil if the markup has no inline elements.
+func Spans(markup string) []Span {
+	if !strings.Contains(markup, "<") {
+		return nil
+	}
+	var spans []Span
+	inline := false
+	stack := []Span{{}}
+	dec := xml.NewDecoder(strings.NewReader(markup))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			s := stack[len(stack)-1]
+			switch t.Name.Local {
+			case "b":
+				s.Bold = true
+			case "i":
+				s.Italic = true
+			case "span":
+				for _, a := range t.Attr {
+					switch a.Name.Local {
+					case "color":
+						s.Color = a.Value
+					case "font":
+						s.Font = a.Value
+					case "opacity":
+						s.Opacity, _ = strconv.ParseFloat(a.Value, 64)
+					case "link":
+						s.Link = a.Value
+					}
+				}
+			}
+			inline = true
+			stack = append(stack, s)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			s := stack[len(stack)-1]
+			s.Text = string(t)
+			spans = append(spans, s)
+		}
+	}
+	if !inline {
+		return nil
+	}
+	return spans
+}
+
+// TextSpans returns the styled runs of text or list item markup, as Spans does,
+// or nil if its plain text is no longer text: the text was changed after reading,
+// and its markup is out of date
+func TextSpans(markup, text string) []Span {
+	spans := Spans(markup)
+	if spans == nil || SpanText(spans) != text {
+		return nil
+	}
+	return spans
+}
+
+// SpanText returns the text of spans, without styling
+func SpanText(spans []Span) string {
+	var b strings.Builder
+	for _, s := range spans {
+		b.WriteString(s.Text)
+	}
+	return b.String()
+}
+
+// flatten sets the plain text of text elements and list items with inline elements
+// to the whole of their text, including that within the inline elements
+func flatten(text []Text, list []List, groups []Group) {
+	for i := range text {
+		if spans := Spans(text[i].Markup); spans != nil {
+			text[i].Tdata = SpanText(spans)
+		}
+	}
+	for i := range list {
+		for j := range list[i].Li {
+			if spans := Spans(list[i].Li[j].Markup); spans != nil {
+				list[i].Li[j].ListText = SpanText(spans)
+			}
+		}
+	}
+	for i := range groups {
+		flatten(groups[i].Text, groups[i].List, groups[i].Group)
+	}
+}
@@ -0,0 +1,46 @@
+package deck
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSpans(t *testing.T) {
+	markup := `<deck><slide>
+<text xp="10" yp="10">Sales are <span color="red" opacity="50">down <b>a &amp; lot</b></span>, <i>sadly</i></text>
+<list><li>plain</li><li><span font="mono" link="http://x.org">x</span></li></list>
+</slide></deck>`
+	d, err := ReadDeckOptions(io.NopCloser(strings.NewReader(markup)), 100, 100, ReadOptions{Strict: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := d.Slide[0]
+	want := []Span{
+		{Text: "Sales are "},
+		{Text: "down ", Color: "red", Opacity: 50},
+		{Text: "a & lot", Color: "red", Opacity: 50, Bold: true},
+		{Text: ", "},
+		{Text: "sadly", Italic: true},
+	}
+	if got := Spans(s.Text[0].Markup); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+	if got := SpanText(want); got != "Sales are down a & lot, sadly" {
+		t.Errorf("SpanText: got %q", got)
+	}
+	if got := s.Text[0].Tdata; got != "Sales are down a & lot, sadly" {
+		t.Errorf("Tdata: got %q", got)
+	}
+	if got := s.List[0].Li[1].ListText; got != "x" {
+		t.Errorf("ListText: got %q", got)
+	}
+	if got := Spans(s.List[0].Li[0].Markup); got != nil {
+		t.Errorf("plain item: got %+v, want nil", got)
+	}
+	want = []Span{{Text: "x", Font: "mono", Link: "http://x.org"}}
+	if got := Spans(s.List[0].Li[1].Markup); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,188 @@
+package deck
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// ReadOptions control how deck markup is read
+type ReadOptions struct {
+	Strict bool // reject unknown elements and attributes
+}
+
+// MarkupError describes a problem at a location in deck markup
+type MarkupError struct {
+	File         string
+	Line, Column int
+	Msg          string
+}
+
+func (e MarkupError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Msg)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Msg)
+}
+
+// MarkupErrors is a list of markup problems, in source order
+type MarkupErrors []MarkupError
+
+func (e MarkupErrors) Error() string {
+	s := make([]string, len(e))
+	for i, m := range e {
+		s[i] = m.Error()
+	}
+	return strings.Join(s, "\n")
+}
+
+// element describes the attributes and children allowed for a deck element;
+// elements with nil attributes and children (for example notes) accept any content.
+type element struct {
+	attrs    map[string]bool
+	children map[string]*element
+}
+
+// schema describes deck markup, derived from the xml tags of the deck types
+var schema = &element{children: map[string]*element{"deck": describe(reflect.TypeOf(Deck{}))}}
+
+func init() {
+	// includes are expanded before decoding, at the deck and slide level
+	include := &element{attrs: map[string]bool{"file": true}, children: map[string]*element{}}
+	d := schema.children["deck"]
+	d.children["include"] = include
+	d.children["slide"].children["include"] = include
+	// an included file may contain a single slide
+	schema.children["slide"] = d.children["slide"]
+
+	// text and list items may contain inline elements, which nest
+	inline := map[string]*element{
+		"b":    {attrs: map[string]bool{}},
+		"i":    {attrs: map[string]bool{}},
+		"span": {attrs: map[string]bool{"color": true, "font": true, "opacity": true, "link": true}},
+	}
+	for _, e := range inline {
+		e.children = inline
+	}
+	for _, parent := range []*element{d.children["slide"], d.children["template"]} {
+		parent.children["text"].children = inline
+		parent.children["list"].children["li"].children = inline
+	}
+}
+
+// described are the element descriptions of struct types, so that recursive types (groups) are described once
+var described = map[reflect.Type]*element{}
+
+// describe builds the element description of a struct type
+func describe(t reflect.Type) *element {
+	if e, ok := described[t]; ok {
+		return e
+	}
+	e := &element{attrs: map[string]bool{}, children: map[string]*element{}}
+	described[t] = e
+	addfields(e, t)
+	return e
+}
+
+// addfields adds the attributes and children of the fields of t,
+// including those of embedded structs
+func addfields(e *element, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			addfields(e, f.Type)
+			continue
+		}
+		tag := strings.Split(f.Tag.Get("xml"), ",")
+		name := tag[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		if len(tag) > 1 && tag[1] == "attr" {
+			e.attrs[name] = true
+			continue
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Slice {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct {
+			e.children[name] = describe(ft)
+		} else {
+			e.children[name] = &element{}
+		}
+	}
+}
+
+// checkMarkup returns the unknown elements and attributes in deck markup,
+// or the position of the first syntax error.
+func checkMarkup(data []byte) MarkupErrors {
+	var errs MarkupErrors
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	stack := []*element{schema}
+	for {
+		line, col := dec.InputPos()
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return errs
+		}
+		if err != nil {
+			line, col = dec.InputPos()
+			if se, ok := err.(*xml.SyntaxError); ok {
+				err = fmt.Errorf("%s", se.Msg)
+			}
+			return append(errs, MarkupError{Line: line, Column: col, Msg: err.Error()})
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := t.Name.Local
+			parent := stack[len(stack)-1]
+			e := parent.children[name]
+			if e == nil {
+				if parent.children != nil {
+					errs = append(errs, MarkupError{Line: line, Column: col, Msg: fmt.Sprintf("unknown element <%s>", name)})
+				}
+				e = &element{}
+			}
+			if e.attrs != nil {
+				for _, a := range t.Attr {
+					if !e.attrs[a.Name.Local] && a.Name.Space == "" {
+						errs = append(errs, MarkupError{Line: line, Column: col, Msg: fmt.Sprintf("unknown attribute %s on <%s>", a.Name.Local, name)})
+					}
+				}
+			}
+			stack = append(stack, e)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+// ReadDeckOptions reads a deck from a io.Reader, according to the options.
+// In strict mode, unknown elements and attributes are reported as MarkupErrors;
+// the deck is decoded in any case.
+func ReadDeckOptions(r io.ReadCloser, w, h int, opts ReadOptions) (Deck, error) {
+	return readDeck(r, "", w, h, opts)
+}
+
+// ReadWithOptions reads the deck description file, according to the options.
+// Markup errors include the file name.
+func ReadWithOptions(filename string, w, h int, opts ReadOptions) (Deck, error) {
+	if filename == "-" {
+		return readDeck(os.Stdin, "", w, h, opts)
+	}
+	r, err := os.Open(filename)
+	if err != nil {
+		return Deck{}, err
+	}
+	return readDeck(r, filename, w, h, opts)
+}
+
+// ReadStrict reads the deck description file, rejecting unknown elements and attributes
+func ReadStrict(filename string, w, h int) (Deck, error) {
+	return ReadWithOptions(filename, w, h, ReadOptions{Strict: true})
+}
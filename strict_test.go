@@ -0,0 +1,64 @@
+package deck
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadStrict(t *testing.T) {
+	markup := `<deck>
+<slide bg="white">
+  <rec xp="10" yp="10"/>
+  <text xP="10" yp="20" type="block">hello</text>
+  <list xp="10" yp="20"><li color="red">item</li></list>
+</slide>
+</deck>`
+	r := io.NopCloser(strings.NewReader(markup))
+	d, err := ReadDeckOptions(r, 100, 100, ReadOptions{Strict: true})
+	errs, ok := err.(MarkupErrors)
+	if !ok {
+		t.Fatalf("got error %v, want MarkupErrors", err)
+	}
+	want := []string{
+		"3:3: unknown element <rec>",
+		"4:3: unknown attribute xP on <text>",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("got %d errors (%v), want %d", len(errs), errs, len(want))
+	}
+	for i, e := range errs {
+		if e.Error() != want[i] {
+			t.Errorf("got %q, want %q", e.Error(), want[i])
+		}
+	}
+	if len(d.Slide) != 1 || len(d.Slide[0].Text) != 1 {
+		t.Errorf("deck not decoded: %+v", d)
+	}
+
+	_, err = ReadDeckOptions(io.NopCloser(strings.NewReader("<deck>\n<slide>\n</deck>")), 100, 100, ReadOptions{Strict: true})
+	if errs, ok := err.(MarkupErrors); !ok || len(errs) != 1 || errs[0].Line != 3 {
+		t.Errorf("syntax error: got %v, want an error on line 3", err)
+	}
+}
+
+func TestReadStrictInclude(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "main.xml")
+	part := filepath.Join(dir, "part.xml")
+	os.WriteFile(main, []byte("<deck>\n<slide><include file=\"part.xml\"/></slide>\n</deck>"), 0644)
+	os.WriteFile(part, []byte("<slide>\n<text xp=\"10\" colr=\"red\">hello</text>\n</slide>"), 0644)
+	d, err := ReadWithOptions(main, 100, 100, ReadOptions{Strict: true})
+	errs, ok := err.(MarkupErrors)
+	if !ok || len(errs) != 1 {
+		t.Fatalf("got error %v, want one MarkupError", err)
+	}
+	if want := part + ":2:1: unknown attribute colr on <text>"; errs[0].Error() != want {
+		t.Errorf("got %q, want %q", errs[0].Error(), want)
+	}
+	if len(d.Slide) != 1 || len(d.Slide[0].Text) != 1 {
+		t.Errorf("deck not decoded: %+v", d)
+	}
+}
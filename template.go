@@ -0,0 +1,79 @@
+package deck
+
+import "fmt"
+
+// Template is a named master slide; its attributes and elements
+// are inherited by slides that refer to it:
+// <template name="title" bg="white">
+//
+//	<image xp="90" yp="90" width="64" height="64" name="logo.png"/>
+//	<text xp="95" yp="5" sp="1.5" align="end">{{slidenumber}}</text>
+//
+// </template>
+// <slide template="title">...</slide>
+type Template struct {
+	Name string `xml:"name,attr" json:"name,omitempty"`
+	Slide
+}
+
+// ApplyTemplates merges templates into the slides that use them.
+// Slide attributes override those of the template, and slide elements
+// are layered over the template's elements, which become the slide's first group (see Group.Master). Variables such as {{slidenumber}}
+// in template text are expanded along with the slide's own text (see Expand).
+func ApplyTemplates(d *Deck) error {
+	templates := make(map[string]Slide, len(d.Template))
+	for _, t := range d.Template {
+		if t.Template != "" {
+			return fmt.Errorf("template %q: templates cannot use other templates", t.Name)
+		}
+		templates[t.Name] = t.Slide
+	}
+	for i := range d.Slide {
+		s := &d.Slide[i]
+		if s.Template == "" {
+			continue
+		}
+		t, ok := templates[s.Template]
+		if !ok {
+			return fmt.Errorf("slide %d: unknown template %q", i+1, s.Template)
+		}
+		inherit(s, t)
+	}
+	return nil
+}
+
+// inherit merges template t into slide s
+func inherit(s *Slide, t Slide) {
+	s.Template = ""
+	if s.Bg == "" {
+		s.Bg = t.Bg
+	}
+	if s.Fg == "" {
+		s.Fg = t.Fg
+	}
+	if s.Gradcolor1 == "" && s.Gradcolor2 == "" {
+		s.Gradient = t.Gradient
+	}
+	if s.Duration == "" {
+		s.Duration = t.Duration
+	}
+	if s.Transition == "" {
+		s.Transition = t.Transition
+	}
+	if s.Dim == 0 {
+		s.Dim = t.Dim
+	}
+	if s.Note == "" {
+		s.Note = t.Note
+	}
+	// the template's elements are a leading group, drawn before the slide's own elements
+	master := Group{
+		List: t.List, Text: t.Text, Image: t.Image, Ellipse: t.Ellipse, Line: t.Line, Rect: t.Rect,
+		Curve: t.Curve, Arc: t.Arc, Polygon: t.Polygon, Polyline: t.Polyline, Path: t.Path,
+		Connector: t.Connector, Table: t.Table, Chart: t.Chart, Group: t.Group, Master: true,
+	}
+	if len(t.List)+len(t.Text)+len(t.Image)+len(t.Ellipse)+len(t.Line)+len(t.Rect)+len(t.Curve)+len(t.Arc)+
+		len(t.Polygon)+len(t.Polyline)+len(t.Path)+len(t.Connector)+len(t.Table)+len(t.Chart)+len(t.Group) > 0 {
+		s.Group = append([]Group{master}, s.Group...)
+	}
+}
@@ -0,0 +1,43 @@
+package deck
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestTemplates(t *testing.T) {
+	markup := `<deck>
+<title>Report</title>
+<template name="std" bg="black" fg="white">
+  <rect xp="50" yp="5" wp="100" hp="10" color="gray"/>
+  <text xp="95" yp="5" sp="1">{{slidenumber}}/{{slidecount}} {{title}}</text>
+</template>
+<slide template="std" fg="red">
+  <text xp="10" yp="50" sp="3">first</text>
+</slide>
+<slide template="std"/>
+</deck>`
+	d, err := ReadDeck(io.NopCloser(strings.NewReader(markup)), 100, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the template's elements are the first group, under the slide's own elements
+	s := d.Slide[0]
+	if s.Bg != "black" || s.Fg != "red" || len(s.Group) != 1 || !s.Group[0].Master || len(s.Group[0].Rect) != 1 {
+		t.Errorf("slide 1 did not inherit attributes and elements: bg=%q fg=%q groups=%+v", s.Bg, s.Fg, s.Group)
+	}
+	if len(s.Text) != 1 || s.Text[0].Tdata != "first" {
+		t.Errorf("slide 1 text: %+v", s.Text)
+	}
+	for i, want := range []string{"1/2 Report", "2/2 Report"} {
+		if got := Expand(d.Slide[i].Group[0].Text[0].Tdata, d, i); got != want {
+			t.Errorf("slide %d text: got %q, want %q", i+1, got, want)
+		}
+	}
+
+	_, err = ReadDeck(io.NopCloser(strings.NewReader(`<deck><slide template="none"/></deck>`)), 100, 100)
+	if err == nil || !strings.Contains(err.Error(), `unknown template "none"`) {
+		t.Errorf("unknown template: got %v", err)
+	}
+}
@@ -0,0 +1,447 @@
+package deck
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Problem describes an error found in a deck by Validate.
+// Slide is the index of the slide (-1 for the deck itself),
+// Kind is the element name (for example "rect" or "list"), preceded by those of enclosing groups ("group 1: rect"),
+// and Index is the position of the element among those of the same kind on the slide
+// (-1 for problems with the slide itself).
+type Problem struct {
+	Slide int
+	Kind  string
+	Index int
+	Msg   string
+}
+
+func (p Problem) String() string {
+	switch {
+	case p.Slide < 0:
+		return fmt.Sprintf("deck: %s", p.Msg)
+	case p.Index < 0:
+		return fmt.Sprintf("slide %d: %s", p.Slide+1, p.Msg)
+	}
+	return fmt.Sprintf("slide %d: %s %d: %s", p.Slide+1, p.Kind, p.Index+1, p.Msg)
+}
+
+// fonts are the font names understood by the renderers
+var fonts = map[string]bool{"": true, "sans": true, "serif": true, "mono": true, "symbol": true}
+
+// alignments are the alignments of table columns and cells
+var alignments = map[string]bool{"begin": true, "start": true, "left": true, "center": true, "c": true, "end": true, "right": true}
+
+// validator collects problems
+type validator struct {
+	problems []Problem
+	slide    int
+	kind     string
+	index    int
+	group    string // location of the enclosing groups
+}
+
+func (v *validator) at(kind string, index int) {
+	v.kind = v.group + kind
+	v.index = index
+}
+
+func (v *validator) add(format string, args ...interface{}) {
+	v.problems = append(v.problems, Problem{Slide: v.slide, Kind: v.kind, Index: v.index, Msg: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) color(attr, s string) {
+	if s == "" {
+		return
+	}
+	if _, err := ParseColor(s); err != nil {
+		v.add("%s: %v", attr, err)
+	}
+}
+
+func (v *validator) opacity(o float64) {
+	if o > 100 {
+		v.add("opacity %v is greater than 100", o)
+	}
+}
+
+func (v *validator) font(f string) {
+	if !fonts[f] {
+		v.add("unknown font %q", f)
+	}
+}
+
+// spans checks the styles of inline spans; prefix locates the content
+func (v *validator) spans(prefix, markup string) {
+	for _, sp := range Spans(markup) {
+		if _, err := ParseColor(sp.Color); sp.Color != "" && err != nil {
+			v.add("%sspan color: %v", prefix, err)
+		}
+		if sp.Opacity > 100 {
+			v.add("%sspan opacity %v is greater than 100", prefix, sp.Opacity)
+		}
+		if !fonts[sp.Font] {
+			v.add("%sspan: unknown font %q", prefix, sp.Font)
+		}
+	}
+}
+
+func (v *validator) file(attr, name string) {
+	if name == "" || strings.Contains(name, "://") {
+		return
+	}
+	if _, err := os.Stat(name); err != nil {
+		v.add("%s: %v", attr, err)
+	}
+}
+
+func (v *validator) common(c CommonAttr) {
+	v.color("color", c.Color)
+	v.step(c.Step)
+	v.gradient(c.Gradient)
+	v.opacity(c.Opacity)
+	v.font(c.Font)
+	if c.Sp < 0 {
+		v.add("negative size %v", c.Sp)
+	}
+}
+
+func (v *validator) step(n int) {
+	if n < 0 {
+		v.add("negative step %d", n)
+	}
+}
+
+// gradient checks gradient colors and types
+func (v *validator) gradient(g Gradient) {
+	v.color("gradcolor1", g.Gradcolor1)
+	v.color("gradcolor2", g.Gradcolor2)
+	switch g.GradType {
+	case "", "linear", "radial":
+	default:
+		v.add("unknown gradtype %q", g.GradType)
+	}
+}
+
+func (v *validator) dimension(d Dimension) {
+	v.common(d.CommonAttr)
+	if d.Wp < 0 || d.Hp < 0 || d.Hr < 0 || d.Hw < 0 {
+		v.add("negative dimension")
+	}
+}
+
+// linestyle checks dash patterns, line caps and line joins
+func (v *validator) linestyle(ls LineStyle) {
+	for _, d := range strings.Fields(ls.Dash) {
+		if f, err := strconv.ParseFloat(d, 64); err != nil || f < 0 {
+			v.add("bad dash length %q", d)
+		}
+	}
+	switch ls.Linecap {
+	case "", "butt", "round", "square":
+	default:
+		v.add("unknown linecap %q", ls.Linecap)
+	}
+	switch ls.Linejoin {
+	case "", "miter", "round", "bevel":
+	default:
+		v.add("unknown linejoin %q", ls.Linejoin)
+	}
+}
+
+// arrowhead checks the arrowheads of a line
+func (v *validator) arrowhead(a Arrowhead) {
+	switch a.Arrow {
+	case "", "none", "start", "end", "both":
+	default:
+		v.add("unknown arrow %q", a.Arrow)
+	}
+	switch a.ArrowStyle {
+	case "", "filled", "open", "dot":
+	default:
+		v.add("unknown arrowstyle %q", a.ArrowStyle)
+	}
+	if a.ArrowSize < 0 {
+		v.add("negative arrowsize %v", a.ArrowSize)
+	}
+}
+
+// id records the id of an element that connectors may join
+func (v *validator) id(ids map[string]bool, id string) {
+	if id == "" {
+		return
+	}
+	if ids[id] {
+		v.add("duplicate id %q", id)
+	}
+	ids[id] = true
+}
+
+// outline checks the outline and fill of a shape
+func (v *validator) outline(o Outline) {
+	v.color("stroke", o.Stroke)
+	if o.Fill != "none" {
+		v.color("fill", o.Fill)
+	}
+	if o.StrokeWidth < 0 {
+		v.add("negative strokewidth %v", o.StrokeWidth)
+	}
+	v.linestyle(o.LineStyle)
+}
+
+// coords checks that a pair of coordinate strings have
+// the same number of values, and at least min points.
+func (v *validator) coords(xc, yc string, min int) {
+	xs, ys := strings.Fields(xc), strings.Fields(yc)
+	if len(xs) != len(ys) {
+		v.add("xc has %d values, yc has %d", len(xs), len(ys))
+		return
+	}
+	if len(xs) < min {
+		v.add("%d points, need at least %d", len(xs), min)
+	}
+	for _, s := range append(xs, ys...) {
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			v.add("bad coordinate %q", s)
+		}
+	}
+}
+
+// Validate checks a deck for problems that the renderers silently ignore:
+// malformed colors, out of range opacity, unknown fonts, mismatched polygon coordinates, malformed paths, unconnected connectors,
+// unparsable durations, and missing image, text and chart data files.
+// File names are relative to the current directory.
+func Validate(d Deck) []Problem {
+	v := &validator{slide: -1, index: -1}
+	if d.Canvas.Width <= 0 || d.Canvas.Height <= 0 {
+		v.add("canvas dimensions %dx%d must be positive", d.Canvas.Width, d.Canvas.Height)
+	}
+	for i, s := range d.Slide {
+		v.slide = i
+		v.at("slide", -1)
+		v.color("bg", s.Bg)
+		v.color("fg", s.Fg)
+		v.gradient(s.Gradient)
+		if s.Dim > 100 {
+			v.add("dim %v is greater than 100", s.Dim)
+		}
+		if s.Duration != "" {
+			if _, err := time.ParseDuration(s.Duration); err != nil {
+				v.add("duration: %v", err)
+			}
+		}
+		switch s.Transition {
+		case "", "dissolve", "wipe", "fade", "push", "cover", "uncover", "split", "blinds", "box", "glitter":
+		default:
+			v.add("unknown transition %q", s.Transition)
+		}
+		v.elements(s)
+	}
+	return v.problems
+}
+
+// elements checks the elements of a slide or group
+func (v *validator) elements(s Slide) {
+	ids := map[string]bool{}
+	for j, im := range s.Image {
+		v.at("image", j)
+		v.common(im.CommonAttr)
+		v.id(ids, im.ID)
+		v.file("name", im.Name)
+		if im.Width < 0 || im.Height < 0 || im.Scale < 0 {
+			v.add("negative dimension")
+		}
+	}
+	for j, r := range s.Rect {
+		v.at("rect", j)
+		v.dimension(r.Dimension)
+		v.id(ids, r.ID)
+		v.outline(r.Outline)
+	}
+	for j, e := range s.Ellipse {
+		v.at("ellipse", j)
+		v.dimension(e.Dimension)
+		v.id(ids, e.ID)
+		v.outline(e.Outline)
+	}
+	for j, c := range s.Curve {
+		v.at("curve", j)
+		v.step(c.Step)
+		v.color("color", c.Color)
+		v.opacity(c.Opacity)
+		v.linestyle(c.LineStyle)
+		v.arrowhead(c.Arrowhead)
+	}
+	for j, a := range s.Arc {
+		v.at("arc", j)
+		v.dimension(a.Dimension)
+		v.opacity(a.Opacity)
+		v.linestyle(a.LineStyle)
+	}
+	for j, l := range s.Line {
+		v.at("line", j)
+		v.step(l.Step)
+		v.color("color", l.Color)
+		v.opacity(l.Opacity)
+		v.linestyle(l.LineStyle)
+		v.arrowhead(l.Arrowhead)
+	}
+	for j, p := range s.Polygon {
+		v.at("polygon", j)
+		v.step(p.Step)
+		v.color("color", p.Color)
+		v.opacity(p.Opacity)
+		v.outline(p.Outline)
+		v.gradient(p.Gradient)
+		v.coords(p.XC, p.YC, 3)
+	}
+	for j, p := range s.Polyline {
+		v.at("polyline", j)
+		v.step(p.Step)
+		v.color("color", p.Color)
+		v.opacity(p.Opacity)
+		v.linestyle(p.LineStyle)
+		v.arrowhead(p.Arrowhead)
+		v.coords(p.XC, p.YC, 2)
+	}
+	for j, p := range s.Path {
+		v.at("path", j)
+		v.step(p.Step)
+		v.color("color", p.Color)
+		v.opacity(p.Opacity)
+		v.outline(p.Outline)
+		v.gradient(p.Gradient)
+		if p.D == "" {
+			v.add("no path data")
+		}
+		if _, err := ParsePath(p.D); err != nil {
+			v.add("%v", err)
+		}
+	}
+	for j, c := range s.Connector {
+		v.at("connector", j)
+		v.step(c.Step)
+		v.color("color", c.Color)
+		v.opacity(c.Opacity)
+		v.linestyle(c.LineStyle)
+		v.arrowhead(c.Arrowhead)
+		if !ids[c.From] {
+			v.add("from: no rect, ellipse or image with id %q", c.From)
+		}
+		if !ids[c.To] {
+			v.add("to: no rect, ellipse or image with id %q", c.To)
+		}
+	}
+	for j, t := range s.Text {
+		v.at("text", j)
+		v.common(t.CommonAttr)
+		v.file("file", t.File)
+		v.spans("", t.Markup)
+		switch t.Type {
+		case "", "plain", "block", "code", "markdown":
+		default:
+			v.add("unknown type %q", t.Type)
+		}
+	}
+	for j, l := range s.List {
+		v.at("list", j)
+		v.common(l.CommonAttr)
+		if l.Build < 0 {
+			v.add("negative build step %d", l.Build)
+		}
+		switch l.Type {
+		case "", "plain", "text", "bullet", "number":
+		default:
+			v.add("unknown type %q", l.Type)
+		}
+		for k, li := range l.Li {
+			if _, err := ParseColor(li.Color); li.Color != "" && err != nil {
+				v.add("item %d: color: %v", k+1, err)
+			}
+			if li.Opacity > 100 {
+				v.add("item %d: opacity %v is greater than 100", k+1, li.Opacity)
+			}
+			if !fonts[li.Font] {
+				v.add("item %d: unknown font %q", k+1, li.Font)
+			}
+			v.spans(fmt.Sprintf("item %d: ", k+1), li.Markup)
+		}
+	}
+	for j, t := range s.Table {
+		v.at("table", j)
+		v.common(t.CommonAttr)
+		v.color("border", t.Border)
+		v.color("stripe", t.Stripe)
+		if t.Wp < 0 || t.Header < 0 {
+			v.add("negative dimension")
+		}
+		for _, w := range strings.Fields(t.Widths) {
+			if f, err := strconv.ParseFloat(w, 64); err != nil || f < 0 {
+				v.add("bad column width %q", w)
+			}
+		}
+		for _, a := range strings.Fields(t.Aligns) {
+			if !alignments[a] {
+				v.add("unknown alignment %q", a)
+			}
+		}
+		for k, tr := range t.Tr {
+			v.color(fmt.Sprintf("row %d: bg", k+1), tr.Bg)
+			v.color(fmt.Sprintf("row %d: color", k+1), tr.Color)
+			if !fonts[tr.Font] {
+				v.add("row %d: unknown font %q", k+1, tr.Font)
+			}
+			for c, td := range tr.Td {
+				prefix := fmt.Sprintf("row %d, cell %d: ", k+1, c+1)
+				v.color(prefix+"bg", td.Bg)
+				v.color(prefix+"color", td.Color)
+				if !fonts[td.Font] {
+					v.add("%sunknown font %q", prefix, td.Font)
+				}
+				if td.Align != "" && !alignments[td.Align] {
+					v.add("%sunknown alignment %q", prefix, td.Align)
+				}
+			}
+		}
+	}
+	for j, c := range s.Chart {
+		v.at("chart", j)
+		v.common(c.CommonAttr)
+		switch c.Type {
+		case "", "bar", "line", "scatter", "pie":
+		default:
+			v.add("unknown type %q", c.Type)
+		}
+		if c.Data == "" {
+			v.add("no data file")
+		}
+		v.file("data", c.Data)
+		for _, color := range ColorList(c.Colors) {
+			v.color("colors", color)
+		}
+		if c.Wp < 0 || c.Hp < 0 {
+			v.add("negative dimension")
+		}
+		if c.Max != 0 && c.Max <= c.Min {
+			v.add("max %v is not greater than min %v", c.Max, c.Min)
+		}
+	}
+	for j, g := range s.Group {
+		v.at("group", j)
+		v.step(g.Step)
+		v.color("color", g.Color)
+		v.opacity(g.Opacity)
+		v.font(g.Font)
+		if g.Scale < 0 {
+			v.add("negative scale %v", g.Scale)
+		}
+		outer := v.group
+		v.group = fmt.Sprintf("%sgroup %d: ", outer, j+1)
+		v.elements(g.Elements())
+		v.group = outer
+	}
+}
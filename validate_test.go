@@ -0,0 +1,57 @@
+package deck
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	var d Deck
+	d.Canvas.Width, d.Canvas.Height = 1024, 768
+	var s Slide
+	s.Bg = "blak"
+	s.Duration = "2s"
+	s.Transition = "spin"
+	s.Rect = []Rect{{}, {}}
+	s.Rect[0].Outline = Outline{Fill: "none", Stroke: "blck", LineStyle: LineStyle{Dash: "4 -2"}}
+	s.Rect[1].Opacity = 150
+	s.Line = []Line{{LineStyle: LineStyle{Linecap: "flat"}, Step: -1}}
+	s.Path = []Path{{D: "M 10 10 L 20"}}
+	s.Rect[1].ID = "box"
+	s.Connector = []Connector{{From: "box", To: "nosuch", Arrowhead: Arrowhead{Arrow: "tail"}}}
+	s.Polygon = []Polygon{{XC: "10 20 30", YC: "10 20", Gradient: Gradient{GradType: "conic"}}}
+	s.List = []List{{Li: []ListItem{{Color: "red"}, {Font: "helvetica"}, {Markup: `a <span color="bleu">b</span>`}}}}
+	s.Table = []Table{{Widths: "30 x", Tr: []TableRow{{Td: []TableCell{{}, {Align: "middle"}}}}}}
+	s.Chart = []Chart{{Data: "testdata/nosuch.csv", Colors: "red bleu"}}
+	s.Group = []Group{{Group: []Group{{Rect: []Rect{{}}}}}}
+	s.Group[0].Group[0].Rect[0].Color = "grene"
+	d.Slide = []Slide{{}, s}
+
+	want := []string{
+		`slide 2: bg: unknown color "blak"`,
+		`slide 2: unknown transition "spin"`,
+		`slide 2: rect 1: stroke: unknown color "blck"`,
+		`slide 2: rect 1: bad dash length "-2"`,
+		"slide 2: rect 2: opacity 150 is greater than 100",
+		"slide 2: line 1: negative step -1",
+		`slide 2: line 1: unknown linecap "flat"`,
+		`slide 2: polygon 1: unknown gradtype "conic"`,
+		"slide 2: polygon 1: xc has 3 values, yc has 2",
+		"slide 2: path 1: bad path: want a number at end",
+		`slide 2: connector 1: unknown arrow "tail"`,
+		`slide 2: connector 1: to: no rect, ellipse or image with id "nosuch"`,
+		`slide 2: list 1: item 2: unknown font "helvetica"`,
+		`slide 2: list 1: item 3: span color: unknown color "bleu"`,
+		`slide 2: table 1: bad column width "x"`,
+		`slide 2: table 1: row 1, cell 2: unknown alignment "middle"`,
+		"slide 2: chart 1: data: stat testdata/nosuch.csv: no such file or directory",
+		`slide 2: chart 1: colors: unknown color "bleu"`,
+		`slide 2: group 1: group 1: rect 1: color: unknown color "grene"`,
+	}
+	problems := Validate(d)
+	if len(problems) != len(want) {
+		t.Fatalf("got %d problems %v, want %d", len(problems), problems, len(want))
+	}
+	for i, p := range problems {
+		if p.String() != want[i] {
+			t.Errorf("problem %d: got %q, want %q", i, p, want[i])
+		}
+	}
+}
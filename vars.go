@@ -0,0 +1,25 @@
+package deck
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Expand replaces the variables in s with their values for slide n (counting from zero) of the deck:
+//
+//	{{slidenumber}}	the slide number, counting from one
+//	{{slidecount}}	the number of slides
+//	{{title}}	the deck title
+//	{{date}}	the deck date
+//
+// Other text, including unknown variables, is unchanged.
+func Expand(s string, d Deck, n int) string {
+	if !strings.Contains(s, "{{") {
+		return s
+	}
+	return strings.NewReplacer(
+		"{{slidenumber}}", strconv.Itoa(n+1),
+		"{{slidecount}}", strconv.Itoa(len(d.Slide)),
+		"{{title}}", d.Title,
+		"{{date}}", d.Date).Replace(s)
+}
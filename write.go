@@ -0,0 +1,166 @@
+package deck
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// escapes for character data and attribute values
+var (
+	textesc = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	attresc = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;",
+		"\n", "&#xA;", "\r", "&#xD;", "\t", "&#x9;")
+)
+
+// Write writes a deck as canonical deck markup: elements are indented
+// by tabs, with attributes in a fixed order; attributes with zero values,
+// which mean the same as an absent attribute, are omitted.
+// Reading the markup produces an equivalent deck.
+func Write(w io.Writer, d Deck) error {
+	data, err := Marshal(d)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Marshal returns the canonical deck markup of a deck (see Write)
+func Marshal(d Deck) ([]byte, error) {
+	var buf bytes.Buffer
+	writeElement(&buf, "deck", reflect.ValueOf(d), 0)
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// field is a struct field with its xml name and kind
+type field struct {
+	name string
+	kind string // attr, chardata, innerxml, or element
+	v    reflect.Value
+}
+
+// xmlfields returns the xml fields of a struct value, in order,
+// including those of embedded structs; as in encoding/xml,
+// a field hides fields of the same name in embedded structs
+func xmlfields(v reflect.Value) []field {
+	var fs []field
+	t := v.Type()
+	outer := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); !f.Anonymous {
+			outer[strings.Split(f.Tag.Get("xml"), ",")[0]] = true
+		}
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			for _, ef := range xmlfields(v.Field(i)) {
+				if ef.name == "" || !outer[ef.name] {
+					fs = append(fs, ef)
+				}
+			}
+			continue
+		}
+		tag := strings.Split(f.Tag.Get("xml"), ",")
+		kind := "element"
+		if len(tag) > 1 && tag[1] != "" {
+			kind = tag[1]
+		}
+		if tag[0] == "-" || (tag[0] == "" && kind == "element") {
+			continue
+		}
+		fs = append(fs, field{name: tag[0], kind: kind, v: v.Field(i)})
+	}
+	return fs
+}
+
+// formatattr formats an attribute value; zero values are omitted
+func formatattr(v reflect.Value) (string, bool) {
+	if v.IsZero() {
+		return "", false
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Float64, reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), true
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true
+	}
+	return "", false
+}
+
+// elementcontent returns the content of an element: its character data, or its inner markup
+// if that has inline elements and still matches the character data
+func elementcontent(fs []field) (string, bool) {
+	var text, markup string
+	for _, f := range fs {
+		switch f.kind {
+		case "chardata":
+			text = f.v.String()
+		case "innerxml":
+			markup = f.v.String()
+		}
+	}
+	if TextSpans(markup, text) != nil {
+		return markup, true
+	}
+	return text, false
+}
+
+// writeElement writes a struct as the named element, indented by depth tabs
+func writeElement(w *bytes.Buffer, name string, v reflect.Value, depth int) {
+	indent := strings.Repeat("\t", depth)
+	fs := xmlfields(v)
+	w.WriteString(indent + "<" + name)
+	for _, f := range fs {
+		if f.kind != "attr" {
+			continue
+		}
+		if s, ok := formatattr(f.v); ok {
+			w.WriteString(" " + f.name + `="` + attresc.Replace(s) + `"`)
+		}
+	}
+	// children
+	var children bytes.Buffer
+	for _, f := range fs {
+		if f.kind != "element" {
+			continue
+		}
+		switch f.v.Kind() {
+		case reflect.String:
+			if s := f.v.String(); s != "" {
+				children.WriteString(indent + "\t<" + f.name + ">" + textesc.Replace(s) + "</" + f.name + ">\n")
+			}
+		case reflect.Struct:
+			if !f.v.IsZero() {
+				writeElement(&children, f.name, f.v, depth+1)
+				children.WriteByte('\n')
+			}
+		case reflect.Slice:
+			for i := 0; i < f.v.Len(); i++ {
+				writeElement(&children, f.name, f.v.Index(i), depth+1)
+				children.WriteByte('\n')
+			}
+		}
+	}
+	text, raw := elementcontent(fs)
+	switch {
+	case children.Len() > 0:
+		w.WriteString(">\n")
+		w.Write(children.Bytes())
+		w.WriteString(indent + "</" + name + ">")
+	case text != "" && raw:
+		w.WriteString(">" + text + "</" + name + ">")
+	case text != "":
+		w.WriteString(">" + textesc.Replace(text) + "</" + name + ">")
+	default:
+		w.WriteString("/>")
+	}
+}
@@ -0,0 +1,94 @@
+package deck
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	markup := `<deck>
+<title>Q &amp; A</title>
+<canvas width="1024" height="768"/>
+<template name="t" bg="black"><text xp="95" yp="5" sp="1.5" align="end">{{slidenumber}}</text></template>
+<slide bg="white" gradcolor1="red" gradcolor2="blue" gp="50" duration="2.5s">
+<note>say "hi"</note>
+<text xp="10" yp="90" sp="3" type="code">a &lt; b
+	c</text>
+<text xp="10" yp="80">Sales are <span color="red">down</span> <b>5%</b></text>
+<list xp="10" yp="70" type="bullet"><li color="red">one</li><li>two</li></list>
+<image xp="50" yp="50" width="100" height="80" name="a.png" caption="A &quot;picture&quot;"/>
+<rect xp="10" yp="10" wp="5" hr="100" opacity="-1"/>
+<polyline xc="10 20 30" yc="40 50 40" sp="0.25" color="#333"/>
+</slide>
+</deck>`
+	d, err := ReadDeck(io.NopCloser(strings.NewReader(markup)), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<deck>
+	<title>Q &amp; A</title>
+	<canvas width="1024" height="768"/>
+	<template name="t" bg="black">
+		<text xp="95" yp="5" sp="1.5" align="end">{{slidenumber}}</text>
+	</template>
+	<slide bg="white" gradcolor1="red" gradcolor2="blue" gp="50" duration="2.5s">
+		<note>say "hi"</note>
+		<list xp="10" yp="70" type="bullet">
+			<li color="red">one</li>
+			<li>two</li>
+		</list>
+		<text xp="10" yp="90" sp="3" type="code">a &lt; b
+	c</text>
+		<text xp="10" yp="80">Sales are <span color="red">down</span> <b>5%</b></text>
+		<image xp="50" yp="50" width="100" height="80" name="a.png" caption="A &quot;picture&quot;"/>
+		<rect xp="10" yp="10" opacity="-1" wp="5" hr="100"/>
+		<polyline xc="10 20 30" yc="40 50 40" sp="0.25" color="#333"/>
+	</slide>
+</deck>
+`
+	if string(data) != want {
+		t.Errorf("got\n%s\nwant\n%s", data, want)
+	}
+
+	// the written deck reads as the original
+	d2, err := ReadDeck(io.NopCloser(bytes.NewReader(data)), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(unmarkup(d), unmarkup(d2)) {
+		t.Errorf("round trip: got\n%+v\nwant\n%+v", d2, d)
+	}
+
+	// changed text is written in place of its original markup
+	d.Slide[0].Text[1].Tdata = "Sales are up"
+	data, _ = Marshal(d)
+	if !bytes.Contains(data, []byte(`<text xp="10" yp="80">Sales are up</text>`)) {
+		t.Errorf("changed text not written:\n%s", data)
+	}
+}
+
+// unmarkup clears the inner markup of text and list items,
+// which may differ in its escapes
+func unmarkup(d Deck) Deck {
+	for i := range d.Slide {
+		s := &d.Slide[i]
+		for j := range s.Text {
+			if Spans(s.Text[j].Markup) == nil {
+				s.Text[j].Markup = ""
+			}
+		}
+		for j := range s.List {
+			for k := range s.List[j].Li {
+				s.List[j].Li[k].Markup = ""
+			}
+		}
+	}
+	return d
+}